@@ -0,0 +1,77 @@
+// Command promisegraph 把 promisegraph.Recorder 保存的 JSON 依赖关系图渲染为 Graphviz DOT 或文本时间线。
+//
+//	promisegraph -format dot trace.json | dot -Tsvg -o flow.svg
+//	promisegraph -format timeline < trace.json
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/xigexb/go-promise/promise/promisegraph"
+)
+
+func main() {
+	format := flag.String("format", "dot", "output format: dot or timeline")
+	out := flag.String("o", "", "write output to file instead of stdout")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: promisegraph [-format dot|timeline] [-o file] [trace.json]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if err := run(*format, *out, flag.Args()); err != nil {
+		fmt.Fprintln(os.Stderr, "promisegraph:", err)
+		os.Exit(1)
+	}
+}
+
+func run(format, out string, args []string) (err error) {
+	// 先校验参数，避免格式错误时留下空的输出文件
+	var write func(*promisegraph.Graph, io.Writer) error
+	switch format {
+	case "dot":
+		write = (*promisegraph.Graph).WriteDOT
+	case "timeline":
+		write = (*promisegraph.Graph).WriteTimeline
+	default:
+		return fmt.Errorf("unknown format %q (want dot or timeline)", format)
+	}
+
+	var in io.Reader = os.Stdin
+	switch len(args) {
+	case 0:
+	case 1:
+		f, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	default:
+		return fmt.Errorf("expected at most one input file, got %d", len(args))
+	}
+
+	g, err := promisegraph.ReadJSON(in)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if out != "" {
+		f, err := os.Create(out)
+		if err != nil {
+			return err
+		}
+		// 写入文件时 Close 才会报告部分写入错误 (如磁盘已满)
+		defer func() {
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+		}()
+		w = f
+	}
+	return write(g, w)
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testdata/trace.json 为 Recorder 输出格式的固定依赖关系图，trace.dot / trace.timeline 为期望输出
+func TestRun_Formats(t *testing.T) {
+	for _, format := range []string{"dot", "timeline"} {
+		t.Run(format, func(t *testing.T) {
+			out := filepath.Join(t.TempDir(), "out")
+			if err := run(format, out, []string{"testdata/trace.json"}); err != nil {
+				t.Fatal(err)
+			}
+			got, err := os.ReadFile(out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := os.ReadFile(filepath.Join("testdata", "trace."+format))
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Windows 上检出的 testdata 可能被转换为 CRLF
+			if string(got) != strings.ReplaceAll(string(want), "\r\n", "\n") {
+				t.Errorf("-format %s output mismatch\ngot:\n%s\nwant:\n%s", format, got, want)
+			}
+		})
+	}
+}
+
+func TestRun_Errors(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out")
+	if err := run("svg", out, []string{"testdata/trace.json"}); err == nil {
+		t.Error("expected error for unknown format")
+	}
+	if _, err := os.Stat(out); !os.IsNotExist(err) {
+		t.Errorf("unknown format must not create the output file, stat err = %v", err)
+	}
+	if err := run("dot", out, []string{"testdata/missing.json"}); err == nil {
+		t.Error("expected error for missing input")
+	}
+	if err := run("dot", out, []string{"a.json", "b.json"}); err == nil {
+		t.Error("expected error for more than one input")
+	}
+}
@@ -1,3 +1,3 @@
 module github.com/xigexb/go-promise
 
-go 1.20
+go 1.21
@@ -1,141 +1,172 @@
 package promise
 
 import (
-	"errors"
+	"fmt"
 	"sync/atomic"
 )
 
 // attachHandler 侵入式挂载回调 (Helper for Aggregators)
-// 直接访问 Promise 私有字段，避免 New Promise 开销
+// 直接访问 Promise 私有字段，避免 New Promise 开销；已决议时在当前 Goroutine 直接执行
 func attachHandler[T any](p *Promise[T], handler func()) {
-	if p.GetState() != Pending {
+	if p.GetState() != Pending || !p.pushHandler(handler) {
 		handler()
-		return
-	}
-
-	p.mu.Lock()
-	if p.state != uint32(Pending) {
-		p.mu.Unlock()
-		handler()
-	} else {
-		node := getHandlerNode(handler)
-		node.next = p.handlers
-		p.handlers = node
-		p.mu.Unlock()
 	}
 }
 
 // Map 泛型转换
+// 与 Then 相同，mapper 直接挂载在上游 Promise 上 (不创建内部 Then，也不为挂载回调派发任务)；
+// mapper 发生 Panic 时 Map 返回的 Promise 以 Rejected 决议
 func Map[T any, R any](p *Promise[T], mapper func(T) (R, error)) *Promise[R] {
-	return New(func(resolve func(R), reject func(error)) {
-		p.Then(func(val T) T {
-			if res, err := mapper(val); err != nil {
-				reject(err)
-			} else {
-				resolve(res)
-			}
-			return val
-		}, func(err error) error {
-			reject(err)
-			return err
-		})
-	})
+	child := &Promise[R]{inline: p.inline, priority: p.priority}
+	child.observe(nil, "Map", p.obs)
+
+	handle := func() {
+		defer handlePanic(child.obs, child.Reject)
+		if p.GetState() != Fulfilled {
+			child.rejectPropagated(p.err)
+			return
+		}
+		start := child.handlerStart()
+		res, err := mapper(p.val)
+		child.handlerDone("Map", start)
+		if err != nil {
+			child.Reject(err)
+		} else {
+			child.Resolve(res)
+		}
+	}
+	react(p, child, p.inline, handle)
+
+	return child
 }
 
+// 聚合操作直接在调用方 Goroutine 上把回调挂载到每个输入 Promise，输入决议时在决议方 Goroutine 上执行，
+// 不派发任何任务：聚合 N 个 Promise 不会产生额外的 Goroutine
+
 // All 极致优化版
 func All[T any](promises ...*Promise[T]) *Promise[[]T] {
-	return New(func(resolve func([]T), reject func(error)) {
-		count := len(promises)
-		if count == 0 {
-			resolve([]T{})
-			return
-		}
+	child := &Promise[[]T]{}
+	observeJoin(child, "All", promises)
+	child.priority = highestPriority(promises)
+
+	count := len(promises)
+	if count == 0 {
+		child.Resolve([]T{})
+		return child
+	}
 
-		results := make([]T, count)
-		// Fix ST1023: Use short variable declaration for inferred type
-		pending := int32(count)
-		var doneFlag int32 = 0 // 0: running, 1: done (rejected or finished)
+	results := make([]T, count)
+	// Fix ST1023: Use short variable declaration for inferred type
+	pending := int32(count)
+	var doneFlag int32 = 0 // 0: running, 1: done (rejected or finished)
 
-		for i, p := range promises {
-			idx := i
-			target := p
+	for i, p := range promises {
+		idx := i
+		target := p
 
-			handler := func() {
-				if target.state == uint32(Fulfilled) {
-					// 如果已经失败过，直接返回
-					if atomic.LoadInt32(&doneFlag) == 1 {
-						return
-					}
-					results[idx] = target.val
-					// 最后一个完成
-					if atomic.AddInt32(&pending, -1) == 0 {
-						if atomic.CompareAndSwapInt32(&doneFlag, 0, 1) {
-							resolve(results)
-						}
-					}
-				} else {
-					// 只要有一个 Rejected，整体 Rejected
+		handler := func() {
+			if target.state == uint32(Fulfilled) {
+				// 如果已经失败过，直接返回
+				if atomic.LoadInt32(&doneFlag) == 1 {
+					return
+				}
+				results[idx] = target.val
+				// 最后一个完成
+				if atomic.AddInt32(&pending, -1) == 0 {
 					if atomic.CompareAndSwapInt32(&doneFlag, 0, 1) {
-						reject(target.err)
+						child.Resolve(results)
 					}
 				}
+			} else {
+				// 只要有一个 Rejected，整体 Rejected
+				if atomic.CompareAndSwapInt32(&doneFlag, 0, 1) {
+					child.rejectPropagated(target.err)
+				}
 			}
-			attachHandler(target, handler)
 		}
-	})
+		attachHandler(target, handler)
+	}
+	return child
+}
+
+// AggregateError 多个任务全部失败时的聚合错误 (Any、FirstSuccessful)
+// 实现了 Unwrap() []error，可直接使用 errors.Is / errors.As 匹配其中任一错误
+type AggregateError struct {
+	Errors []error
+}
+
+func (e *AggregateError) Error() string {
+	if len(e.Errors) == 0 {
+		return "aggregate error: no promises"
+	}
+	return fmt.Sprintf("aggregate error: all %d attempts failed", len(e.Errors))
+}
+
+func (e *AggregateError) Unwrap() []error {
+	return e.Errors
 }
 
 // Any 极致优化版
+// 全部失败时以 *AggregateError 拒绝，Errors 按 promises 的顺序排列
 func Any[T any](promises ...*Promise[T]) *Promise[T] {
-	return New(func(resolve func(T), reject func(error)) {
-		if len(promises) == 0 {
-			reject(errors.New("aggregate error: no promises"))
-			return
-		}
+	child := &Promise[T]{}
+	observeJoin(child, "Any", promises)
+	child.priority = highestPriority(promises)
 
-		// Fix ST1023: Use short variable declaration
-		pending := int32(len(promises))
-		var successFlag int32 = 0
+	if len(promises) == 0 {
+		child.Reject(&AggregateError{})
+		return child
+	}
 
-		for _, p := range promises {
-			target := p
-			handler := func() {
-				if target.state == uint32(Fulfilled) {
-					if atomic.CompareAndSwapInt32(&successFlag, 0, 1) {
-						resolve(target.val)
-					}
-				} else {
-					if atomic.AddInt32(&pending, -1) == 0 {
-						if atomic.LoadInt32(&successFlag) == 0 {
-							reject(errors.New("aggregate error: all promises rejected"))
-						}
+	// Fix ST1023: Use short variable declaration
+	pending := int32(len(promises))
+	var successFlag int32 = 0
+	// 各任务只写自己的下标，最后一个失败者在 pending 归零后读取全部
+	errs := make([]error, len(promises))
+
+	for i, p := range promises {
+		idx, target := i, p
+		handler := func() {
+			if target.state == uint32(Fulfilled) {
+				if atomic.CompareAndSwapInt32(&successFlag, 0, 1) {
+					child.Resolve(target.val)
+				}
+			} else {
+				errs[idx] = target.err
+				if atomic.AddInt32(&pending, -1) == 0 {
+					if atomic.LoadInt32(&successFlag) == 0 {
+						child.Reject(&AggregateError{Errors: errs})
 					}
 				}
 			}
-			attachHandler(target, handler)
 		}
-	})
+		attachHandler(target, handler)
+	}
+	return child
 }
 
 // Race 极致优化版
 func Race[T any](promises ...*Promise[T]) *Promise[T] {
-	return New(func(resolve func(T), reject func(error)) {
-		var doneFlag int32 = 0
+	child := &Promise[T]{}
+	observeJoin(child, "Race", promises)
+	child.priority = highestPriority(promises)
 
-		for _, p := range promises {
-			target := p
-			handler := func() {
-				if atomic.CompareAndSwapInt32(&doneFlag, 0, 1) {
-					if target.state == uint32(Fulfilled) {
-						resolve(target.val)
-					} else {
-						reject(target.err)
-					}
+	var doneFlag int32 = 0
+
+	for _, p := range promises {
+		target := p
+		handler := func() {
+			if atomic.CompareAndSwapInt32(&doneFlag, 0, 1) {
+				if target.state == uint32(Fulfilled) {
+					child.Resolve(target.val)
+				} else {
+					child.rejectPropagated(target.err)
 				}
 			}
-			attachHandler(target, handler)
 		}
-	})
+		attachHandler(target, handler)
+	}
+	return child
 }
 
 type SettledResult[T any] struct {
@@ -146,33 +177,36 @@ type SettledResult[T any] struct {
 
 // AllSettled 极致优化版
 func AllSettled[T any](promises ...*Promise[T]) *Promise[[]SettledResult[T]] {
-	return New(func(resolve func([]SettledResult[T]), reject func(error)) {
-		count := len(promises)
-		if count == 0 {
-			resolve([]SettledResult[T]{})
-			return
-		}
+	child := &Promise[[]SettledResult[T]]{}
+	observeJoin(child, "AllSettled", promises)
+	child.priority = highestPriority(promises)
+
+	count := len(promises)
+	if count == 0 {
+		child.Resolve([]SettledResult[T]{})
+		return child
+	}
 
-		results := make([]SettledResult[T], count)
-		// Fix ST1023: Use short variable declaration
-		pending := int32(count)
+	results := make([]SettledResult[T], count)
+	// Fix ST1023: Use short variable declaration
+	pending := int32(count)
 
-		for i, p := range promises {
-			idx := i
-			target := p
+	for i, p := range promises {
+		idx := i
+		target := p
 
-			handler := func() {
-				if target.state == uint32(Fulfilled) {
-					results[idx] = SettledResult[T]{Status: Fulfilled, Value: target.val}
-				} else {
-					results[idx] = SettledResult[T]{Status: Rejected, Reason: target.err}
-				}
+		handler := func() {
+			if target.state == uint32(Fulfilled) {
+				results[idx] = SettledResult[T]{Status: Fulfilled, Value: target.val}
+			} else {
+				results[idx] = SettledResult[T]{Status: Rejected, Reason: target.err}
+			}
 
-				if atomic.AddInt32(&pending, -1) == 0 {
-					resolve(results)
-				}
+			if atomic.AddInt32(&pending, -1) == 0 {
+				child.Resolve(results)
 			}
-			attachHandler(target, handler)
 		}
-	})
+		attachHandler(target, handler)
+	}
+	return child
 }
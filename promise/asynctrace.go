@@ -0,0 +1,100 @@
+package promise
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+)
+
+// -------------------------------------------------------
+// 异步调用栈：记录拒绝原因沿 Then / Map / Finally / 聚合传播经过的每一跳
+// -------------------------------------------------------
+
+// asyncTraceEnabled 异步调用栈开关，见 SetAsyncStackTraces
+var asyncTraceEnabled atomic.Bool
+
+// SetAsyncStackTraces 开启/关闭异步调用栈
+// 开启后每个新建的 Promise 都会记录创建位置，拒绝原因在链路上每传播一跳都会被包装为新的 *AsyncTrace，
+// 可通过 errors.As(err, &trace) 取出，类似 V8 的 async stack trace。errors.Is 仍可匹配原始错误。
+// 每次创建 Promise 都会调用 runtime.Callers，适合排障时开启。
+func SetAsyncStackTraces(on bool) {
+	asyncTraceEnabled.Store(on)
+}
+
+// AsyncFrame 异步调用栈中的一跳
+type AsyncFrame struct {
+	ID   uint64
+	Op   string
+	Name string
+	Site CallSite
+}
+
+func (f AsyncFrame) String() string {
+	op := f.Op
+	if f.Name != "" {
+		op = fmt.Sprintf("%s %q", f.Op, f.Name)
+	}
+	return fmt.Sprintf("%s (%s)", op, f.Site)
+}
+
+// AsyncTrace 携带异步调用栈的拒绝原因
+// Frames[0] 为最初产生错误的 Promise，之后依次为错误传播经过的 Promise
+type AsyncTrace struct {
+	Err    error
+	Frames []AsyncFrame
+}
+
+// Error 只返回原始错误信息，完整调用栈见 Stack 或 %+v
+func (e *AsyncTrace) Error() string {
+	return e.Err.Error()
+}
+
+func (e *AsyncTrace) Unwrap() error {
+	return e.Err
+}
+
+// Stack 渲染异步调用栈，例如：
+//
+//	db down
+//	    at New "load-user" (user.go:42)
+//	    at Then (handler.go:10)
+//	    at Map (handler.go:11)
+func (e *AsyncTrace) Stack() string {
+	var b strings.Builder
+	b.WriteString(e.Err.Error())
+	for _, f := range e.Frames {
+		b.WriteString("\n    at ")
+		b.WriteString(f.String())
+	}
+	return b.String()
+}
+
+// Format 实现 fmt.Formatter，%+v 输出完整异步调用栈
+func (e *AsyncTrace) Format(s fmt.State, verb rune) {
+	switch {
+	case verb == 'v' && s.Flag('+'):
+		_, _ = io.WriteString(s, e.Stack())
+	case verb == 'q':
+		fmt.Fprintf(s, "%q", e.Error())
+	default:
+		_, _ = io.WriteString(s, e.Error())
+	}
+}
+
+// traceRejection 追加当前 Promise 作为异步调用栈的一跳
+// 同一个错误可能扇出到多个子 Promise，因此每一跳都复制 Frames 而不是原地追加
+func (p *Promise[T]) traceRejection(err error) error {
+	if err == nil || p.obs == nil || !asyncTraceEnabled.Load() {
+		return err
+	}
+
+	frame := AsyncFrame{ID: p.obs.id, Op: p.obs.op, Name: p.obs.name, Site: p.obs.site}
+	if at, ok := err.(*AsyncTrace); ok {
+		frames := make([]AsyncFrame, len(at.Frames)+1)
+		copy(frames, at.Frames)
+		frames[len(at.Frames)] = frame
+		return &AsyncTrace{Err: at.Err, Frames: frames}
+	}
+	return &AsyncTrace{Err: err, Frames: []AsyncFrame{frame}}
+}
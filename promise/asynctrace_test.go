@@ -0,0 +1,80 @@
+package promise
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestAsyncTrace_Hops(t *testing.T) {
+	setGlobal(t, SetAsyncStackTraces, asyncTraceEnabled.Load(), true)
+
+	cause := errors.New("db down")
+	root := New(func(resolve func(int), reject func(error)) { reject(cause) }, WithName("load-user"))
+	then := root.Then(func(v int) int { return v + 1 }, nil)
+	fin := then.Finally(func() {})
+	mapped := Map(fin, func(v int) (string, error) { return "", nil })
+
+	_, err := mapped.Await(context.Background())
+	if !errors.Is(err, cause) {
+		t.Fatalf("expected cause to match, got %v", err)
+	}
+	// -tags promisedebug 时错误还会附带创建位置，只校验前缀
+	if !strings.HasPrefix(err.Error(), "db down") {
+		t.Errorf("message unchanged: got %q", err.Error())
+	}
+
+	var at *AsyncTrace
+	if !errors.As(err, &at) {
+		t.Fatalf("expected *AsyncTrace, got %T", err)
+	}
+
+	var ops []string
+	for _, f := range at.Frames {
+		ops = append(ops, f.Op)
+		if !strings.HasSuffix(f.Site.File, "asynctrace_test.go") {
+			t.Errorf("hop %s should point at test file, got %s", f.Op, f.Site)
+		}
+	}
+	assertEqual(t, "New Then Finally Map", strings.Join(ops, " "), "hops")
+	assertEqual(t, "load-user", at.Frames[0].Name, "origin name")
+
+	stack := fmt.Sprintf("%+v", err)
+	if !strings.HasPrefix(stack, "db down") || !strings.Contains(stack, "\n    at New \"load-user\" (") || strings.Count(stack, "\n    at ") != 4 {
+		t.Errorf("unexpected stack:\n%s", stack)
+	}
+}
+
+func TestAsyncTrace_FanOutIndependent(t *testing.T) {
+	setGlobal(t, SetAsyncStackTraces, asyncTraceEnabled.Load(), true)
+
+	root := Reject[int](errors.New("x"))
+	a := root.Then(nil, nil)
+	b := root.Then(nil, nil).Then(nil, nil)
+
+	_, errA := a.Await(context.Background())
+	_, errB := b.Await(context.Background())
+
+	var ta, tb *AsyncTrace
+	errors.As(errA, &ta)
+	errors.As(errB, &tb)
+	if ta == nil || tb == nil {
+		t.Fatalf("expected traces, got %v / %v", errA, errB)
+	}
+	assertEqual(t, 2, len(ta.Frames), "branch a hops")
+	assertEqual(t, 3, len(tb.Frames), "branch b hops")
+}
+
+func TestAsyncTrace_Disabled(t *testing.T) {
+	cause := errors.New("x")
+	_, err := Reject[int](cause).Then(nil, nil).Await(context.Background())
+	if !errors.Is(err, cause) {
+		t.Fatalf("expected cause, got %v", err)
+	}
+	var at *AsyncTrace
+	if errors.As(err, &at) {
+		t.Errorf("no wrapping when disabled, got %+v", err)
+	}
+}
@@ -0,0 +1,106 @@
+package promise
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWait(t *testing.T) {
+	p := &Promise[int]{}
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		p.Resolve(7)
+	}()
+	v, err := p.Wait()
+	if err != nil || v != 7 {
+		t.Fatalf("expected 7, got %v, %v", v, err)
+	}
+
+	cause := errors.New("x")
+	v, err = Reject[int](cause).Wait()
+	if !errors.Is(err, cause) || v != 0 {
+		t.Fatalf("expected rejection, got %v, %v", v, err)
+	}
+}
+
+func TestAwait_CanceledWaitersDoNotAccumulate(t *testing.T) {
+	p := &Promise[int]{}
+
+	for i := 0; i < 10000; i++ {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := p.Await(ctx); !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected canceled, got %v", err)
+		}
+	}
+	for i := 0; i < 5; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		if _, err := p.Await(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected deadline exceeded, got %v", err)
+		}
+		cancel()
+	}
+
+	// 提前返回的 Await 共享同一个等待节点，不会在 Pending Promise 上越积越多
+	nodes := 0
+	p.mu.Lock()
+	for n := p.handlers; n != nil; n = n.next {
+		nodes++
+	}
+	p.mu.Unlock()
+	if nodes != 1 {
+		t.Fatalf("expected 1 shared waiter node, got %d", nodes)
+	}
+
+	// 之后的等待者不受影响
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ctx := context.Background()
+			if i%2 == 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithCancel(ctx)
+				defer cancel()
+			}
+			if v, err := p.Await(ctx); err != nil || v != 1 {
+				t.Errorf("expected 1, got %v, %v", v, err)
+			}
+		}(i)
+	}
+	time.Sleep(5 * time.Millisecond)
+	p.Resolve(1)
+	wg.Wait()
+
+	if v, err := p.Wait(); err != nil || v != 1 {
+		t.Fatalf("expected 1 after settle, got %v, %v", v, err)
+	}
+}
+
+func TestAwait_WakesBeforeHandlers(t *testing.T) {
+	p := &Promise[int]{}
+	release := make(chan struct{})
+	p.Then(func(v int) int {
+		<-release // 在决议方的 Goroutine 上阻塞
+		return v
+	}, nil)
+
+	done := make(chan struct{})
+	go func() {
+		_, _ = p.Wait()
+		close(done)
+	}()
+	time.Sleep(5 * time.Millisecond)
+	go p.Resolve(1)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("waiter blocked behind a slow handler")
+	}
+	close(release)
+}
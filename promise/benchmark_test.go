@@ -2,7 +2,10 @@ package promise
 
 import (
 	"context"
+	"runtime"
+	"sync"
 	"testing"
+	"time"
 )
 
 // 1. 基准：最简单的 Resolve
@@ -89,3 +92,223 @@ func BenchmarkPromise_Async_ShortTask(b *testing.B) {
 		_, _ = p.Await(context.Background())
 	}
 }
+
+// 7. 热点订阅：大量 Goroutine 同时在同一个 Pending Promise 上注册回调
+// 配合 -cpu 1,4,16 观察回调注册的争用情况
+func BenchmarkPromise_HotSubscribe(b *testing.B) {
+	b.ReportAllocs()
+	hot := &Promise[int]{}
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			hot.Then(nil, nil)
+		}
+	})
+
+	b.StopTimer()
+	hot.Resolve(1)
+}
+
+// 8. 内联链式调用：在已决议的 Promise 上逐跳构建长度为 b.N 的 ThenSync 链，每 maxInlineDepth 跳退回一次派发
+func BenchmarkPromise_Chain_Deep_Sync(b *testing.B) {
+	p := Resolve(0)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		p = p.ThenSync(func(v int) int {
+			return v + 1
+		}, nil)
+	}
+	if v, err := p.Await(context.Background()); err != nil || v != b.N {
+		b.Fatalf("expected %d, got %v, %v", b.N, v, err)
+	}
+}
+
+// 9. 组合开销：100 个 Map 汇入 Race / Any / All / Timeout (输入尚未决议)，统计每次组合派发的任务数 (默认调度器下即 Goroutine 数)
+func BenchmarkPromise_Compose_MapAll(b *testing.B) {
+	d := &countingDispatcher{}
+	setGlobal[TaskDispatcher](b, SetDispatcher, GlobalDispatcher, d)
+
+	b.ReportAllocs()
+	inputs := make([]*Promise[string], 100)
+	for i := 0; i < b.N; i++ {
+		root := &Promise[int]{}
+		for j := range inputs {
+			inputs[j] = Map(root, func(v int) (string, error) { return "x", nil })
+		}
+		all := All(Race(inputs...), Any(inputs...)).Timeout(time.Minute, "")
+		root.Resolve(1)
+		_, _ = all.Await(context.Background())
+	}
+	b.ReportMetric(float64(d.n.Load())/float64(b.N), "dispatches/op")
+}
+
+// 10. 在 Pending Promise 上等待：由常驻 Goroutine 决议，等待本身不应分配内存
+func benchmarkAwaitPending(b *testing.B, wait func(p *Promise[int])) {
+	promises := make([]Promise[int], b.N)
+	ch := make(chan *Promise[int])
+	go func() {
+		for p := range ch {
+			p.Resolve(1)
+		}
+	}()
+	defer close(ch)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := range promises {
+		p := &promises[i]
+		ch <- p
+		wait(p)
+	}
+}
+
+func BenchmarkPromise_Await_Pending(b *testing.B) {
+	ctx := context.Background()
+	benchmarkAwaitPending(b, func(p *Promise[int]) { _, _ = p.Await(ctx) })
+}
+
+func BenchmarkPromise_Wait_Pending(b *testing.B) {
+	benchmarkAwaitPending(b, func(p *Promise[int]) { _, _ = p.Wait() })
+}
+
+// 11. 决议扇出：一个 Promise 上挂 10k 个订阅者，配合 -cpu 1,4,16 观察批量派发的效果 (阈值 256)
+func BenchmarkPromise_FanOut_10k(b *testing.B) {
+	const subscribers = 10000
+	SetFanOutThreshold(256)
+	defer SetFanOutThreshold(0)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		p := &Promise[int]{}
+		var wg sync.WaitGroup
+		wg.Add(subscribers)
+		for j := 0; j < subscribers; j++ {
+			p.pushHandler(func() {
+				for k := 0; k < 100; k++ {
+					_ = k * k
+				}
+				wg.Done()
+			})
+		}
+		p.Resolve(1)
+		wg.Wait()
+	}
+}
+
+// 12. 对象池：与 BenchmarkPromise_Resolve 相同，但 Promise 来自 PromisePool 并在读取结果后 Release
+func BenchmarkPromise_Resolve_Pooled(b *testing.B) {
+	pool := NewPromisePool[int]()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		p := pool.New(func(resolve func(int), reject func(error)) {
+			resolve(i)
+		})
+		_, _ = p.Await(context.Background())
+		p.Release()
+	}
+}
+
+// 13. 对象池 + 同步决议：不经过调度器，只衡量 Promise 本身的分配
+func BenchmarkPromise_Resolve_Pooled_Sync(b *testing.B) {
+	pool := NewPromisePool[int]()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		p := pool.Get()
+		p.Resolve(i)
+		_, _ = p.Await(context.Background())
+		p.Release()
+	}
+}
+
+// fixedPool 对照组：固定数量 worker 共享一个通道的协程池
+type fixedPool struct {
+	tasks chan func()
+	wg    sync.WaitGroup
+}
+
+func newFixedPool(workers int) *fixedPool {
+	p := &fixedPool{tasks: make(chan func(), 1024)}
+	p.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer p.wg.Done()
+			for f := range p.tasks {
+				runTask(f)
+			}
+		}()
+	}
+	return p
+}
+
+func (p *fixedPool) Dispatch(f func()) {
+	p.tasks <- f
+}
+
+func (p *fixedPool) Close() {
+	close(p.tasks)
+	p.wg.Wait()
+}
+
+// benchmarkDispatchers 在默认调度器和固定协程池下分别运行 fn
+func benchmarkDispatchers(b *testing.B, fn func(b *testing.B)) {
+	run := func(name string, newDispatcher func() (TaskDispatcher, func())) {
+		b.Run(name, func(b *testing.B) {
+			prev := GlobalDispatcher
+			d, closer := newDispatcher()
+			SetDispatcher(d)
+			defer func() {
+				SetDispatcher(prev)
+				closer()
+			}()
+			b.ReportAllocs()
+			fn(b)
+		})
+	}
+	run("default", func() (TaskDispatcher, func()) {
+		return &defaultDispatcher{}, func() {}
+	})
+	run("fixed", func() (TaskDispatcher, func()) {
+		p := newFixedPool(runtime.GOMAXPROCS(0))
+		return p, p.Close
+	})
+}
+
+// 14. 调度器对比：与 Async_ShortTask 相同的短任务
+func BenchmarkDispatcher_ShortTask(b *testing.B) {
+	benchmarkDispatchers(b, func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			p := New(func(resolve func(int), reject func(error)) {
+				resolve(1)
+			})
+			_, _ = p.Await(context.Background())
+		}
+	})
+}
+
+// 15. 调度器对比：深度为 32 的 Then 链；只有挂载时上游已决议的一跳派发任务，其余回调在决议方 (worker) 上依次执行
+func BenchmarkDispatcher_ThenChain(b *testing.B) {
+	benchmarkDispatchers(b, func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			p := Resolve(0)
+			for j := 0; j < 32; j++ {
+				p = p.Then(func(v int) int { return v + 1 }, nil)
+			}
+			_, _ = p.Await(context.Background())
+		}
+	})
+}
+
+// 16. 调度器对比：并发提交短任务
+func BenchmarkDispatcher_Parallel(b *testing.B) {
+	benchmarkDispatchers(b, func(b *testing.B) {
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				p := New(func(resolve func(int), reject func(error)) {
+					resolve(1)
+				})
+				_, _ = p.Wait()
+			}
+		})
+	})
+}
@@ -1,7 +1,12 @@
 package promise
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // TaskDispatcher 定义任务调度器接口
@@ -10,30 +15,192 @@ type TaskDispatcher interface {
 	Dispatch(func())
 }
 
-// defaultDispatcher 默认使用原生 Goroutine
-type defaultDispatcher struct{}
+// defaultDispatcher 默认使用原生 Goroutine，记录在途任务数以支持 Drain
+type defaultDispatcher struct {
+	inflight atomic.Int64
+
+	mu    sync.Mutex
+	drain drainState // 受 mu 保护
+}
 
 func (d *defaultDispatcher) Dispatch(f func()) {
-	go f()
+	d.inflight.Add(1)
+	go d.run(f)
+}
+
+// DispatchTask 实现 Dispatcher：Drain 之后返回 ErrDispatcherClosed，t.Ctx 已结束时返回 t.Ctx.Err()
+func (d *defaultDispatcher) DispatchTask(t Task) error {
+	if err := t.Ctx.Err(); err != nil {
+		return err
+	}
+	if d.drain.closed.Load() {
+		return ErrDispatcherClosed
+	}
+	d.Dispatch(t.Run)
+	return nil
+}
+
+// DispatchBatch 实现 BatchDispatcher：每一批一个 Goroutine
+func (d *defaultDispatcher) DispatchBatch(tasks []func()) {
+	d.inflight.Add(int64(len(tasks)))
+	for _, f := range tasks {
+		go d.run(f)
+	}
+}
+
+// Drain 实现 Drainer，之后派发的回调仍会各自启动 Goroutine 执行
+// 关闭不可撤销：排空作为 GlobalDispatcher 的默认调度器后，New 会一直以 ErrDispatcherClosed 拒绝，
+// 需要继续创建 Promise 时 (例如测试) 调用 SetDispatcher(nil) 换上新的默认调度器。
+func (d *defaultDispatcher) Drain(ctx context.Context) error {
+	d.mu.Lock()
+	done := d.drain.close()
+	d.drain.notify(d.inflight.Load() == 0)
+	d.mu.Unlock()
+	return waitDrained(ctx, done)
+}
+
+func (d *defaultDispatcher) run(f func()) {
+	defer func() {
+		// 关闭后在途任务归零时通知 Drain
+		if d.inflight.Add(-1) == 0 && d.drain.closed.Load() {
+			d.mu.Lock()
+			d.drain.notify(d.inflight.Load() == 0)
+			d.mu.Unlock()
+		}
+	}()
+	f()
+}
+
+// ErrDispatcherClosed 调度器已关闭，不再接受新任务
+var ErrDispatcherClosed = errors.New("promise: dispatcher closed")
+
+// Task 交给 Dispatcher 的任务
+type Task struct {
+	// Ctx 任务所属 Promise 的 Context (NewWithContext / RateLimited)，其余情况为 context.Background()
+	Ctx context.Context
+	// Priority 任务所属 Promise 的优先级 (WithPriority)
+	Priority Priority
+	Run      func()
+}
+
+// Dispatcher 可拒绝任务的调度器 (可选接口)
+// GlobalDispatcher 实现该接口时，executor (New / NewWithContext / Delay / RateLimited 等) 通过 DispatchTask 提交；
+// 返回错误时任务不会执行，对应的 Promise 以该错误拒绝 (关闭后为 ErrDispatcherClosed，Ctx 已结束时可返回 Ctx.Err())。
+// Promise 反应 (Then / Catch / Finally / Map 的回调) 仍通过 Dispatch 提交且不能被拒绝，保证已有链路在关闭期间能执行完毕。
+type Dispatcher interface {
+	TaskDispatcher
+	DispatchTask(t Task) error
+}
+
+// Drainer 支持优雅关闭的调度器 (可选接口)
+// Drain 停止接受新的 executor (之后 DispatchTask 返回 ErrDispatcherClosed)，等待已提交的任务及其派生的回调全部执行完毕后返回 nil。
+// 回调在此期间仍会被接受，不断派生新回调的链路 (例如自我递归的 Then) 会让 Drain 一直等待，应传入带超时的 ctx；
+// ctx 先结束时返回 ctx.Err()，此时调度器保持关闭，已提交的任务继续执行，可再次调用 Drain 等待。
+// 内置的默认调度器、EventLoop 与 PriorityPool 均实现该接口，进程退出前可统一排空：
+//
+//	if d, ok := promise.GlobalDispatcher.(promise.Drainer); ok {
+//		_ = d.Drain(ctx)
+//	}
+type Drainer interface {
+	Drain(ctx context.Context) error
+}
+
+// drainState 内置调度器共用的 Drain 状态；调度器在自己的锁内判断是否空闲，并在可能变为空闲时调用 notify
+type drainState struct {
+	closed   atomic.Bool   // 拒绝新的 executor
+	quiesced chan struct{} // Drain 等待的信号：关闭后调度器空闲时关闭，受所属调度器的锁保护
+}
+
+// close 拒绝新的 executor，返回 Drain 等待的信号 (调用方需持有所属调度器的锁)
+func (s *drainState) close() <-chan struct{} {
+	s.closed.Store(true)
+	if s.quiesced == nil {
+		s.quiesced = make(chan struct{})
+	}
+	return s.quiesced
+}
+
+// notify 已开始 Drain 且 idle 为 true 时通知等待方 (调用方需持有所属调度器的锁)
+func (s *drainState) notify(idle bool) {
+	if s.quiesced == nil || !idle {
+		return
+	}
+	select {
+	case <-s.quiesced:
+	default:
+		close(s.quiesced)
+	}
+}
+
+// waitDrained 等待 done 关闭，ctx 先结束时返回 ctx.Err()
+func waitDrained(ctx context.Context, done <-chan struct{}) error {
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
 var (
-	// GlobalDispatcher 全局调度器，默认为原生 go func
+	// GlobalDispatcher 全局调度器，默认为原生 go func (支持 Drain，见 Drainer)
 	GlobalDispatcher TaskDispatcher = &defaultDispatcher{}
 )
 
-// SetDispatcher 允许替换全局调度器 (例如注入 ants)
+// SetDispatcher 允许替换全局调度器 (例如注入 ants)，传入 nil 时换上新的默认调度器
 func SetDispatcher(d TaskDispatcher) {
+	if d == nil {
+		d = &defaultDispatcher{}
+	}
 	GlobalDispatcher = d
 }
 
+// Timer 由 Clock.AfterFunc 返回的定时器句柄
+type Timer interface {
+	// Stop 取消定时器，若定时器已触发或已取消则返回 false
+	Stop() bool
+}
+
+// Clock 时钟抽象，Delay / Timeout / TokenBucket 均通过它获取时间和创建定时器
+// 测试中可通过 SetClock 注入假时钟 (见 promisetest 包)，让基于时间的链路确定性执行
+type Clock interface {
+	Now() time.Time
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// realClock 默认时钟，直接使用 time 包
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+var (
+	// GlobalClock 全局时钟，默认为真实时间
+	GlobalClock Clock = realClock{}
+)
+
+// SetClock 允许替换全局时钟 (例如注入测试用的假时钟)
+func SetClock(c Clock) {
+	GlobalClock = c
+}
+
 // handlePanic 统一的 Panic 恢复逻辑，防止 Goroutine 崩溃导致进程退出
-func handlePanic(reject func(error)) {
+// o 为发生 Panic 的回调所属 Promise 的元数据 (可为 nil)，用于通知 Metrics / Observer
+func handlePanic(o *observation, reject func(error)) {
 	if r := recover(); r != nil {
 		err, ok := r.(error)
 		if !ok {
 			err = fmt.Errorf("panic: %v", r)
 		}
+		if m := loadMetrics(); m != nil {
+			m.PromisePanicked()
+		}
+		notifyPanic(o, r)
 		// 确保 reject 存在
 		if reject != nil {
 			reject(err)
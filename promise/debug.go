@@ -0,0 +1,187 @@
+package promise
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// -------------------------------------------------------
+// 调试模式：命名 Promise 与创建位置
+// -------------------------------------------------------
+
+// debugEnabled 调试模式开关，可通过 SetDebug 或 -tags promisedebug 开启
+var debugEnabled atomic.Bool
+
+// SetDebug 开启/关闭调试模式
+// 开启后每个新建的 Promise 都会记录创建位置 (调用栈中第一个库外栈帧)，
+// 拒绝原因会被包装为 *RejectionError 以携带来源 Promise 的名称与创建位置。
+// 调试模式有额外开销 (runtime.Callers)，不建议在生产热路径上长期开启。
+func SetDebug(on bool) {
+	debugEnabled.Store(on)
+}
+
+// DebugEnabled 返回调试模式是否开启
+func DebugEnabled() bool {
+	return debugEnabled.Load()
+}
+
+// Option New / NewWithContext 的可选配置
+type Option func(*options)
+
+type options struct {
+	name     string
+	inline   bool
+	priority Priority
+}
+
+func applyOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithName 为 Promise 设置名称，用于 Info / String 输出和调试模式下的拒绝包装
+// 设置名称的 Promise 总会分配元数据，即使未开启调试模式
+func WithName(name string) Option {
+	return func(o *options) {
+		o.name = name
+	}
+}
+
+// CallSite 源码位置
+type CallSite struct {
+	Function string
+	File     string
+	Line     int
+}
+
+func (c CallSite) String() string {
+	if c.File == "" {
+		return "unknown"
+	}
+	return fmt.Sprintf("%s:%d", c.File, c.Line)
+}
+
+// Info Promise 的调试信息
+type Info struct {
+	ID      uint64
+	Name    string
+	Op      string
+	State   State
+	Created time.Time
+	// Site 创建位置，仅在调试模式下记录
+	Site CallSite
+}
+
+// Info 返回 Promise 的调试信息
+// 未分配元数据的 Promise (未开启钩子/调试模式且未命名) 只有 State 有效
+func (p *Promise[T]) Info() Info {
+	if p.obs == nil {
+		return Info{State: p.GetState()}
+	}
+	return p.obs.info(p.GetState())
+}
+
+func (o *observation) info(state State) Info {
+	return Info{ID: o.id, Name: o.name, Op: o.op, State: state, Created: o.created, Site: o.site}
+}
+
+// String 实现 fmt.Stringer，例如：
+//
+//	Promise#12("load-user" fulfilled: 42) created at main.go:42
+func (p *Promise[T]) String() string {
+	var b strings.Builder
+	b.WriteString("Promise")
+
+	o := p.obs
+	if o != nil && o.id != 0 {
+		fmt.Fprintf(&b, "#%d", o.id)
+	}
+	b.WriteByte('(')
+	if o != nil && o.name != "" {
+		fmt.Fprintf(&b, "%q ", o.name)
+	}
+
+	switch s := p.GetState(); s {
+	case Fulfilled:
+		fmt.Fprintf(&b, "%s: %v", s, p.val)
+	case Rejected:
+		fmt.Fprintf(&b, "%s: %v", s, p.err)
+	default:
+		b.WriteString(s.String())
+	}
+	b.WriteByte(')')
+
+	if o != nil && o.site.File != "" {
+		fmt.Fprintf(&b, " created at %s", o.site)
+	}
+	return b.String()
+}
+
+// RejectionError 调试模式下的拒绝原因包装，记录错误最初产生于哪个 Promise
+// 沿链路传播时不会重复包装；可通过 errors.As 取出，Unwrap 返回原始错误
+type RejectionError struct {
+	Promise Info
+	Err     error
+}
+
+func (e *RejectionError) Error() string {
+	origin := e.Promise.Op
+	if e.Promise.Name != "" {
+		origin = fmt.Sprintf("%q", e.Promise.Name)
+	}
+	if e.Promise.Site.File != "" {
+		return fmt.Sprintf("%v (promise %s created at %s)", e.Err, origin, e.Promise.Site)
+	}
+	return fmt.Sprintf("%v (promise %s)", e.Err, origin)
+}
+
+func (e *RejectionError) Unwrap() error {
+	return e.Err
+}
+
+// wrapRejection 调试模式下包装拒绝原因 (已包装过的不再重复包装)
+func (p *Promise[T]) wrapRejection(err error) error {
+	if err == nil || p.obs == nil || !debugEnabled.Load() {
+		return err
+	}
+	var re *RejectionError
+	if errors.As(err, &re) {
+		return err
+	}
+	info := p.Info()
+	info.State = Rejected
+	return &RejectionError{Promise: info, Err: err}
+}
+
+// captureSite 找到调用栈中第一个库外 (或测试文件中) 的栈帧
+func captureSite() CallSite {
+	var pcs [32]uintptr
+	n := runtime.Callers(3, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !isLibraryFrame(frame) {
+			return CallSite{Function: frame.Function, File: frame.File, Line: frame.Line}
+		}
+		if !more {
+			return CallSite{}
+		}
+	}
+}
+
+// libraryPrefix 本包函数名前缀
+const libraryPrefix = "github.com/xigexb/go-promise/promise."
+
+func isLibraryFrame(frame runtime.Frame) bool {
+	if strings.HasSuffix(frame.File, "_test.go") {
+		return false
+	}
+	return strings.HasPrefix(frame.Function, libraryPrefix)
+}
@@ -0,0 +1,8 @@
+//go:build promisedebug
+
+package promise
+
+// 使用 -tags promisedebug 构建时默认开启调试模式
+func init() {
+	debugEnabled.Store(true)
+}
@@ -0,0 +1,74 @@
+package promise
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDebug_CreationSite(t *testing.T) {
+	setGlobal(t, SetDebug, DebugEnabled(), true)
+
+	p := New(func(resolve func(int), reject func(error)) { resolve(1) }, WithName("load-user"))
+	_, _ = p.Await(context.Background())
+
+	info := p.Info()
+	assertEqual(t, "load-user", info.Name, "name")
+	assertEqual(t, "New", info.Op, "op")
+	if !strings.HasSuffix(info.Site.File, "debug_test.go") || !strings.Contains(info.Site.Function, "TestDebug_CreationSite") {
+		t.Errorf("unexpected creation site: %+v", info.Site)
+	}
+
+	s := fmt.Sprint(p)
+	if !strings.Contains(s, `"load-user" fulfilled: 1`) || !strings.Contains(s, "created at ") {
+		t.Errorf("unexpected String(): %s", s)
+	}
+}
+
+func TestDebug_RejectionWrapping(t *testing.T) {
+	setGlobal(t, SetDebug, DebugEnabled(), true)
+
+	cause := errors.New("db down")
+	p := New(func(resolve func(int), reject func(error)) { reject(cause) }, WithName("load-user")).
+		Then(func(v int) int { return v }, nil)
+
+	_, err := p.Await(context.Background())
+	if !errors.Is(err, cause) {
+		t.Fatalf("wrapped error should match cause, got %v", err)
+	}
+
+	var re *RejectionError
+	if !errors.As(err, &re) {
+		t.Fatalf("expected *RejectionError, got %T", err)
+	}
+	// 记录的是最初产生错误的 Promise，而不是沿链路传播经过的 Then
+	assertEqual(t, "load-user", re.Promise.Name, "origin name")
+	if errors.Unwrap(re) != cause {
+		t.Errorf("wrapped more than once: %v", err)
+	}
+	if !strings.Contains(err.Error(), `db down (promise "load-user" created at `) {
+		t.Errorf("unexpected message: %s", err.Error())
+	}
+}
+
+func TestWithName_WithoutDebug(t *testing.T) {
+	setGlobal(t, SetDebug, DebugEnabled(), false)
+
+	cause := errors.New("x")
+	p := New(func(resolve func(int), reject func(error)) { reject(cause) }, WithName("named"))
+	_, err := p.Await(context.Background())
+
+	assertEqual(t, cause, err, "no wrapping outside debug mode")
+	assertEqual(t, "named", p.Info().Name, "name kept")
+	assertEqual(t, `Promise#`+fmt.Sprint(p.ID())+`("named" rejected: x)`, p.String(), "String()")
+}
+
+func TestPromise_StringUnobserved(t *testing.T) {
+	// 调试模式 (含 -tags promisedebug) 下所有 Promise 都被观察，String 会带上编号
+	setGlobal(t, SetDebug, DebugEnabled(), false)
+
+	assertEqual(t, "Promise(fulfilled: 42)", Resolve(42).String(), "fulfilled")
+	assertEqual(t, "Promise(pending)", (&Promise[int]{}).String(), "pending")
+}
@@ -0,0 +1,9 @@
+// Package promise 提供基于泛型的 Promise 异步原语：New / Then / Await、聚合 (All / Any / Race / AllSettled)
+// 以及可替换的任务调度器 (TaskDispatcher)。
+//
+// # 钩子
+//
+// Tracer (SetTracer)、Metrics (SetMetrics) 与 Observer (AddObserver) 都在触发事件的 Goroutine 上同步调用：
+// 同一个钩子会被多个 Goroutine 并发调用，实现必须是并发安全的；钩子执行期间会阻塞触发它的 executor、回调或调度器 worker，
+// 应尽快返回，耗时的导出工作应交给其它 Goroutine。钩子只作用于设置之后创建的 Promise。
+package promise
@@ -0,0 +1,157 @@
+package promise
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// drainableDispatcher 内置的可排空调度器
+type drainableDispatcher interface {
+	Dispatcher
+	Drainer
+}
+
+func forEachDrainable(t *testing.T, fn func(t *testing.T, d drainableDispatcher)) {
+	cases := []struct {
+		name string
+		new  func(t *testing.T) drainableDispatcher
+	}{
+		{"Default", func(t *testing.T) drainableDispatcher { return &defaultDispatcher{} }},
+		{"EventLoop", func(t *testing.T) drainableDispatcher {
+			loop := NewEventLoop()
+			ctx, cancel := context.WithCancel(context.Background())
+			stopped := make(chan struct{})
+			go func() {
+				defer close(stopped)
+				_ = loop.Run(ctx)
+			}()
+			t.Cleanup(func() {
+				cancel()
+				<-stopped
+			})
+			return loop
+		}},
+		{"PriorityPool", func(t *testing.T) drainableDispatcher { return NewPriorityPool(2, 0) }},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			prev := GlobalDispatcher
+			d := c.new(t)
+			SetDispatcher(d)
+			defer func() {
+				// 先排空再换回调度器，避免仍在执行的回调读取 GlobalDispatcher 时与替换竞争
+				_ = d.Drain(context.Background())
+				SetDispatcher(prev)
+			}()
+			fn(t, d)
+		})
+	}
+}
+
+func TestDrain_WaitsForExecutorsAndContinuations(t *testing.T) {
+	forEachDrainable(t, func(t *testing.T, d drainableDispatcher) {
+		var reacted atomic.Bool
+		p := New(func(resolve func(int), reject func(error)) {
+			time.Sleep(10 * time.Millisecond)
+			// 关闭期间派发的反应仍会被接受并执行
+			Resolve(1).Then(func(v int) int {
+				reacted.Store(true)
+				return v
+			}, nil)
+			resolve(1)
+		})
+
+		if err := d.Drain(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if p.GetState() != Fulfilled || !reacted.Load() {
+			t.Fatalf("Drain returned before queued work finished: state=%v reacted=%v", p.GetState(), reacted.Load())
+		}
+	})
+}
+
+func TestDrain_RejectsNewPromises(t *testing.T) {
+	forEachDrainable(t, func(t *testing.T, d drainableDispatcher) {
+		if err := d.Drain(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+
+		ran := false
+		_, err := New(func(resolve func(int), reject func(error)) {
+			ran = true
+			resolve(1)
+		}).Await(context.Background())
+		if !errors.Is(err, ErrDispatcherClosed) || ran {
+			t.Fatalf("New after Drain: err=%v ran=%v, want ErrDispatcherClosed", err, ran)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		if _, err := NewWithContext(ctx, func(resolve func(int), reject func(error)) { resolve(1) }).Await(ctx); !errors.Is(err, ErrDispatcherClosed) {
+			t.Fatalf("NewWithContext after Drain: err=%v, want ErrDispatcherClosed", err)
+		}
+
+		// 已决议 Promise 上的反应不受影响
+		if v, err := Resolve(1).Then(func(v int) int { return v + 1 }, nil).Await(context.Background()); err != nil || v != 2 {
+			t.Fatalf("Then after Drain = %v, %v", v, err)
+		}
+	})
+}
+
+func TestDrain_ContextDeadline(t *testing.T) {
+	forEachDrainable(t, func(t *testing.T, d drainableDispatcher) {
+		release := make(chan struct{})
+		p := New(func(resolve func(int), reject func(error)) {
+			<-release
+			resolve(1)
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		if err := d.Drain(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("Drain = %v, want DeadlineExceeded", err)
+		}
+
+		// 超时后仍保持关闭，已在执行的任务可以继续完成
+		if _, err := New(func(resolve func(int), reject func(error)) { resolve(1) }).Await(context.Background()); !errors.Is(err, ErrDispatcherClosed) {
+			t.Fatalf("New during drain: err=%v, want ErrDispatcherClosed", err)
+		}
+		close(release)
+		if err := d.Drain(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if p.GetState() != Fulfilled {
+			t.Fatalf("state = %v after Drain, want fulfilled", p.GetState())
+		}
+	})
+}
+
+func TestDispatchTask_CanceledContext(t *testing.T) {
+	forEachDrainable(t, func(t *testing.T, d drainableDispatcher) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if err := d.DispatchTask(Task{Ctx: ctx, Run: func() { t.Error("task ran") }}); !errors.Is(err, context.Canceled) {
+			t.Fatalf("DispatchTask = %v, want context.Canceled", err)
+		}
+	})
+}
+
+func TestDrain_DefaultDispatcherStaysClosed(t *testing.T) {
+	setGlobal[TaskDispatcher](t, SetDispatcher, GlobalDispatcher, nil)
+
+	if err := GlobalDispatcher.(Drainer).Drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New(func(resolve func(int), reject func(error)) { resolve(1) }).Await(context.Background()); !errors.Is(err, ErrDispatcherClosed) {
+		t.Fatalf("New after draining the default dispatcher: err=%v, want ErrDispatcherClosed", err)
+	}
+
+	// SetDispatcher(nil) 换上新的默认调度器
+	SetDispatcher(nil)
+	if v, err := New(func(resolve func(int), reject func(error)) { resolve(1) }).Await(context.Background()); err != nil || v != 1 {
+		t.Fatalf("New after SetDispatcher(nil) = %v, %v", v, err)
+	}
+}
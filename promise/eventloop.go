@@ -0,0 +1,267 @@
+package promise
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// -------------------------------------------------------
+// 单线程事件循环调度器 (微任务 / 宏任务)
+// -------------------------------------------------------
+
+// MicrotaskDispatcher 区分微任务的调度器 (可选接口)
+// GlobalDispatcher 实现该接口时，Promise 反应 (Then / Catch / Finally / Map 的回调，以及决议时唤醒的已注册回调)
+// 通过 DispatchMicrotask 派发，executor 仍通过 Dispatch 派发。
+type MicrotaskDispatcher interface {
+	TaskDispatcher
+	DispatchMicrotask(func())
+}
+
+// ErrLoopRunning 事件循环已在运行时再次调用 Run
+var ErrLoopRunning = errors.New("promise: event loop already running")
+
+// EventLoop 单线程事件循环，提供类似 JavaScript 的确定性执行顺序：
+// 每执行完一个宏任务 (executor、定时器回调) 都会清空微任务队列 (Promise 反应)，
+// 所有任务都在调用 Run 的 Goroutine 上串行执行，每一跳不再需要新的 Goroutine。
+//
+//	loop := promise.NewEventLoop()
+//	promise.SetDispatcher(loop)
+//	promise.SetClock(loop) // Delay / Timeout 的定时器回调也回到事件循环执行
+//	go loop.Run(ctx)
+//
+// 任务可以从任意 Goroutine 提交。不要在事件循环的任务中调用 Await，否则会阻塞事件循环本身导致死锁。
+// 实现 Dispatcher：Drain 之后新的 executor 被拒绝 (New 以 ErrDispatcherClosed 拒绝)，已有链路的回调仍会执行。
+type EventLoop struct {
+	mu       sync.Mutex
+	micro    []func()
+	macro    []func()
+	running  bool
+	stopping bool
+	busy     bool       // 正在执行任务
+	drain    drainState // 关闭后队列为空且没有正在执行的任务时通知 Drain，受 mu 保护
+
+	wake chan struct{}
+}
+
+// NewEventLoop 创建事件循环，需调用 Run (或 RunUntilIdle) 驱动
+func NewEventLoop() *EventLoop {
+	return &EventLoop{wake: make(chan struct{}, 1)}
+}
+
+// Dispatch 提交宏任务，实现 TaskDispatcher
+func (l *EventLoop) Dispatch(f func()) {
+	l.mu.Lock()
+	l.macro = append(l.macro, f)
+	l.mu.Unlock()
+	l.signal()
+}
+
+// DispatchTask 提交 executor (宏任务)，实现 Dispatcher；Drain 之后返回 ErrDispatcherClosed，t.Ctx 已结束时返回 t.Ctx.Err()
+func (l *EventLoop) DispatchTask(t Task) error {
+	if err := t.Ctx.Err(); err != nil {
+		return err
+	}
+	l.mu.Lock()
+	if l.drain.closed.Load() {
+		l.mu.Unlock()
+		return ErrDispatcherClosed
+	}
+	l.macro = append(l.macro, t.Run)
+	l.mu.Unlock()
+	l.signal()
+	return nil
+}
+
+// Drain 实现 Drainer：任务由 Run (或 RunUntilIdle) 执行，Drain 只负责等待；返回后事件循环仍在运行，可取消 Run 的 ctx 或调用 Stop 结束。
+// 尚未到期的定时器 (Delay / Timeout) 不在等待范围内。
+func (l *EventLoop) Drain(ctx context.Context) error {
+	l.mu.Lock()
+	done := l.drain.close()
+	l.drain.notify(l.idle())
+	l.mu.Unlock()
+	return waitDrained(ctx, done)
+}
+
+// DispatchMicrotask 提交微任务，实现 MicrotaskDispatcher
+func (l *EventLoop) DispatchMicrotask(f func()) {
+	l.mu.Lock()
+	l.micro = append(l.micro, f)
+	l.mu.Unlock()
+	l.signal()
+}
+
+// Now 实现 Clock
+func (l *EventLoop) Now() time.Time {
+	return time.Now()
+}
+
+// AfterFunc 实现 Clock：到期后把 f 作为宏任务提交到事件循环
+func (l *EventLoop) AfterFunc(d time.Duration, f func()) Timer {
+	t := &loopTimer{}
+	t.timer = time.AfterFunc(d, func() {
+		l.Dispatch(func() {
+			if t.state.CompareAndSwap(timerArmed, timerFired) {
+				f()
+			}
+		})
+	})
+	return t
+}
+
+// Run 在当前 Goroutine 上运行事件循环，直到 ctx 结束 (返回 ctx.Err()) 或调用 Stop (返回 nil)
+// 队列为空时阻塞等待新任务。同一时刻只能有一个 Run，否则返回 ErrLoopRunning。
+// Run 返回后未执行的任务保留在队列中，可再次调用 Run 继续执行。
+func (l *EventLoop) Run(ctx context.Context) error {
+	l.mu.Lock()
+	if l.running {
+		l.mu.Unlock()
+		return ErrLoopRunning
+	}
+	l.running = true
+	l.stopping = false
+	l.mu.Unlock()
+
+	defer func() {
+		l.mu.Lock()
+		l.running = false
+		l.busy = false
+		l.drain.notify(l.idle())
+		l.mu.Unlock()
+	}()
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		l.mu.Lock()
+		if l.stopping {
+			l.mu.Unlock()
+			return nil
+		}
+		f := l.take()
+		l.mu.Unlock()
+
+		if f != nil {
+			runTask(f)
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-l.wake:
+		}
+	}
+}
+
+// Stop 让正在运行的 Run 在当前任务结束后返回，未在运行时无效果
+func (l *EventLoop) Stop() {
+	l.mu.Lock()
+	if l.running {
+		l.stopping = true
+	}
+	l.mu.Unlock()
+	l.signal()
+}
+
+// RunUntilIdle 在调用方 Goroutine 上执行任务直到两个队列都为空，返回执行的任务数
+// 适合测试或由外部主循环 (GUI / 游戏的每一帧) 驱动，不能与 Run 同时使用
+func (l *EventLoop) RunUntilIdle() int {
+	n := 0
+	for {
+		l.mu.Lock()
+		f := l.take()
+		l.mu.Unlock()
+
+		if f == nil {
+			return n
+		}
+		runTask(f)
+		n++
+	}
+}
+
+// Pending 返回队列中尚未执行的任务数 (微任务 + 宏任务)
+func (l *EventLoop) Pending() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.micro) + len(l.macro)
+}
+
+// take 取出下一个任务并记录是否忙碌，队列为空时检查 Drain 的排空条件 (调用方需持有锁)
+func (l *EventLoop) take() func() {
+	f := l.next()
+	l.busy = f != nil
+	if f == nil {
+		l.drain.notify(l.idle())
+	}
+	return f
+}
+
+// idle 队列为空且没有正在执行的任务 (调用方需持有锁)
+func (l *EventLoop) idle() bool {
+	return !l.busy && len(l.micro) == 0 && len(l.macro) == 0
+}
+
+// next 取出下一个任务：微任务优先 (调用方需持有锁)
+func (l *EventLoop) next() func() {
+	if len(l.micro) > 0 {
+		f := l.micro[0]
+		l.micro[0] = nil
+		l.micro = l.micro[1:]
+		return f
+	}
+	if len(l.macro) > 0 {
+		f := l.macro[0]
+		l.macro[0] = nil
+		l.macro = l.macro[1:]
+		return f
+	}
+	return nil
+}
+
+func (l *EventLoop) signal() {
+	select {
+	case l.wake <- struct{}{}:
+	default:
+	}
+}
+
+// runTask 执行单个任务，Panic 不会中断事件循环 (executor / 回调的 Panic 已由 handlePanic 转为拒绝)
+func runTask(f func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			_ = r
+		}
+	}()
+	f()
+}
+
+const (
+	timerArmed int32 = iota
+	timerFired
+	timerStopped
+)
+
+// loopTimer EventLoop.AfterFunc 返回的定时器
+// 定时器到期但回调尚未在事件循环上执行时 Stop 仍然有效
+type loopTimer struct {
+	timer *time.Timer
+	state atomic.Int32
+}
+
+func (t *loopTimer) Stop() bool {
+	t.timer.Stop()
+	return t.state.CompareAndSwap(timerArmed, timerStopped)
+}
+
+// 编译期检查
+var (
+	_ MicrotaskDispatcher = (*EventLoop)(nil)
+	_ Dispatcher          = (*EventLoop)(nil)
+	_ Clock               = (*EventLoop)(nil)
+)
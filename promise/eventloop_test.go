@@ -0,0 +1,101 @@
+package promise
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEventLoop_MicrotaskOrdering(t *testing.T) {
+	loop := NewEventLoop()
+	setGlobal[TaskDispatcher](t, SetDispatcher, GlobalDispatcher, loop)
+	setGlobal[Clock](t, SetClock, GlobalClock, loop)
+
+	var order []string
+	step := func(name string) func(int) int {
+		return func(v int) int {
+			order = append(order, name)
+			return v
+		}
+	}
+
+	// 与 JavaScript 相同：两条独立链路的反应交替执行
+	a, b := Resolve(1), Resolve(2)
+	a.Then(step("a1"), nil).Then(step("a2"), nil)
+	b.Then(step("b1"), nil).Then(step("b2"), nil)
+
+	// 宏任务 (executor) 在当前所有微任务之后执行
+	New(func(resolve func(int), reject func(error)) {
+		order = append(order, "executor")
+		resolve(0)
+	}).Then(step("executor.then"), nil)
+
+	loop.RunUntilIdle()
+
+	got := strings.Join(order, " ")
+	want := "a1 b1 a2 b2 executor executor.then"
+	if got != want {
+		t.Fatalf("order = %q, want %q", got, want)
+	}
+}
+
+func TestEventLoop_RunAndStop(t *testing.T) {
+	loop := NewEventLoop()
+	setGlobal[TaskDispatcher](t, SetDispatcher, GlobalDispatcher, loop)
+	setGlobal[Clock](t, SetClock, GlobalClock, loop)
+
+	done := make(chan error, 1)
+	go func() { done <- loop.Run(context.Background()) }()
+
+	p := Delay(5*time.Millisecond).Then(func(v struct{}) struct{} { return v }, nil)
+	if _, err := p.Await(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Delay 的定时器回调由事件循环执行，能走到这里说明 Run 已在运行
+	if err := loop.Run(context.Background()); err != ErrLoopRunning {
+		t.Fatalf("expected ErrLoopRunning, got %v", err)
+	}
+
+	loop.Stop()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Run returned %v after Stop", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after Stop")
+	}
+}
+
+func TestEventLoop_RunContextCanceled(t *testing.T) {
+	loop := NewEventLoop()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := loop.Run(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestEventLoop_TimerStopBeforeRun(t *testing.T) {
+	loop := NewEventLoop()
+	fired := false
+	timer := loop.AfterFunc(time.Millisecond, func() { fired = true })
+
+	// 定时器已到期、回调已排队但尚未在事件循环上执行
+	for loop.Pending() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if !timer.Stop() {
+		t.Fatal("Stop should cancel a queued timer callback")
+	}
+	loop.RunUntilIdle()
+	if fired {
+		t.Fatal("stopped timer fired")
+	}
+	if timer.Stop() {
+		t.Fatal("second Stop should report false")
+	}
+}
@@ -2,6 +2,7 @@ package promise_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -50,8 +51,9 @@ func ExamplePromise_Timeout() {
 
 	_, err := p.Timeout(100*time.Millisecond, "timeout").Await(context.Background())
 
-	if err != nil {
-		fmt.Println(err.Error())
+	var te *promise.TimeoutError
+	if errors.As(err, &te) {
+		fmt.Println(te.Msg)
 	}
 
 	// Output:
@@ -7,63 +7,76 @@ import (
 
 // Resolve 静态方法
 func Resolve[T any](val T) *Promise[T] {
-	return &Promise[T]{
+	p := &Promise[T]{
 		state: uint32(Fulfilled),
 		val:   val,
 	}
+	p.observe(nil, "Resolve", nil)
+	p.observeSettle(false)
+	return p
 }
 
 // Reject 静态方法
 func Reject[T any](err error) *Promise[T] {
-	return &Promise[T]{
+	p := &Promise[T]{
 		state: uint32(Rejected),
-		err:   err,
 	}
+	p.observe(nil, "Reject", nil)
+	p.err = p.traceRejection(p.wrapRejection(err))
+	p.observeSettle(false)
+	return p
 }
 
 // Delay 延迟 Promise
 func Delay(d time.Duration) *Promise[struct{}] {
-	return New(func(resolve func(struct{}), reject func(error)) {
-		time.AfterFunc(d, func() {
+	p := &Promise[struct{}]{}
+	p.observe(nil, "Delay", nil)
+	return p.run("Delay", func(resolve func(struct{}), reject func(error)) {
+		GlobalClock.AfterFunc(d, func() {
 			resolve(struct{}{})
 		})
 	})
 }
 
-// Timeout 超时控制
-func (p *Promise[T]) Timeout(d time.Duration, msg string) *Promise[T] {
-	return New(func(resolve func(T), reject func(error)) {
-		timer := time.NewTimer(d)
-		defer timer.Stop() // 确保 timer 资源释放
+// ErrTimeout 超时错误哨兵，可通过 errors.Is(err, ErrTimeout) 判断是否为 Timeout 产生的拒绝
+var ErrTimeout = errors.New("promise timeout")
 
-		done := make(chan struct{})
+// TimeoutError Timeout 超时时的拒绝原因
+type TimeoutError struct {
+	Msg   string // Timeout 传入的自定义信息，为空时使用默认信息
+	After time.Duration
+}
 
-		p.Then(func(val T) T {
-			select {
-			case done <- struct{}{}:
-				resolve(val)
-			default:
-			}
-			return val
-		}, func(err error) error {
-			select {
-			case done <- struct{}{}:
-				reject(err)
-			default:
-			}
-			return err
-		})
+func (e *TimeoutError) Error() string {
+	if e.Msg != "" {
+		return e.Msg
+	}
+	return ErrTimeout.Error()
+}
 
-		select {
-		case <-done:
-		case <-timer.C:
-			errMsg := "promise timeout"
-			if msg != "" {
-				errMsg = msg
-			}
-			reject(errors.New(errMsg))
+// Is 使 errors.Is(err, ErrTimeout) 成立
+func (e *TimeoutError) Is(target error) bool {
+	return target == ErrTimeout
+}
+
+// Timeout 超时控制
+// 定时器由 GlobalClock 创建，原任务先结束时会停止定时器；回调直接挂载在 p 上，不派发任务
+func (p *Promise[T]) Timeout(d time.Duration, msg string) *Promise[T] {
+	child := &Promise[T]{inline: p.inline, priority: p.priority}
+	child.observe(nil, "Timeout", p.obs)
+
+	timer := GlobalClock.AfterFunc(d, func() {
+		child.Reject(&TimeoutError{Msg: msg, After: d})
+	})
+	attachHandler(p, func() {
+		timer.Stop() // 确保 timer 资源释放
+		if p.GetState() == Fulfilled {
+			child.Resolve(p.val)
+		} else {
+			child.rejectPropagated(p.err)
 		}
 	})
+	return child
 }
 
 // Tap 副作用钩子 (不改变值)
@@ -79,7 +92,9 @@ func (p *Promise[T]) Tap(onTap func(val T, err error)) *Promise[T] {
 
 // Promisify 将标准 Go 函数转为 Promise
 func Promisify[T any](f func() (T, error)) *Promise[T] {
-	return New(func(resolve func(T), reject func(error)) {
+	p := &Promise[T]{}
+	p.observe(nil, "Promisify", nil)
+	return p.run("Promisify", func(resolve func(T), reject func(error)) {
 		val, err := f()
 		if err != nil {
 			reject(err)
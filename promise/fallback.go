@@ -0,0 +1,139 @@
+package promise
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// FallbackOptions FirstSuccessfulWith 的可选配置
+type FallbackOptions struct {
+	// StepTimeout 单个数据源的超时时间，0 表示不限制
+	StepTimeout time.Duration
+	// ShouldFallback 判断某个错误是否允许继续尝试下一个数据源
+	// 为 nil 时任何错误都会继续尝试；返回 false 时立即以该错误拒绝
+	ShouldFallback func(error) bool
+}
+
+// FirstSuccessful 依次尝试各个数据源，返回第一个成功的结果
+// 与 Any 不同，后一个工厂只有在前一个失败后才会被调用。
+// 全部失败时以 *AggregateError 拒绝。
+func FirstSuccessful[T any](ctx context.Context, factories ...func(context.Context) *Promise[T]) *Promise[T] {
+	return FirstSuccessfulWith(ctx, FallbackOptions{}, factories...)
+}
+
+// FirstSuccessfulWith 带配置的 FirstSuccessful
+// 各步之间通过回调衔接 (单步超时由 GlobalClock 定时器实现)，等待数据源期间不占用调度器的 worker，可与 EventLoop 一起使用。
+func FirstSuccessfulWith[T any](ctx context.Context, opts FallbackOptions, factories ...func(context.Context) *Promise[T]) *Promise[T] {
+	p := &Promise[T]{}
+	p.observe(ctx, "FirstSuccessful", nil)
+
+	f := &fallback[T]{
+		ctx:       ctx,
+		opts:      opts,
+		factories: factories,
+		errs:      make([]error, 0, len(factories)),
+		p:         p,
+	}
+	if err := p.dispatchFactory(ctx, func() { f.step(0) }); err != nil {
+		p.Reject(err)
+	}
+	return p
+}
+
+// fallback FirstSuccessfulWith 的执行状态，各步依次执行，errs 只由当前一步访问
+type fallback[T any] struct {
+	ctx       context.Context
+	opts      FallbackOptions
+	factories []func(context.Context) *Promise[T]
+	errs      []error
+	p         *Promise[T]
+}
+
+// step 启动第 i 个数据源，它决议、单步超时或外部 Context 结束 (先到者) 时进入 next
+func (f *fallback[T]) step(i int) {
+	defer handlePanic(f.p.obs, f.p.Reject)
+
+	if err := f.ctx.Err(); err != nil {
+		f.p.Reject(err)
+		return
+	}
+	if i == len(f.factories) {
+		f.p.Reject(&AggregateError{Errors: f.errs})
+		return
+	}
+
+	stepCtx, cancel := context.WithCancelCause(f.ctx)
+	var timer Timer
+	if f.opts.StepTimeout > 0 {
+		timer = GlobalClock.AfterFunc(f.opts.StepTimeout, func() {
+			cancel(context.DeadlineExceeded)
+		})
+	}
+
+	// once 保证定时器、ctx 与数据源三者中只有一个推进到下一步
+	var once atomic.Bool
+	release := func() bool {
+		if !once.CompareAndSwap(false, true) {
+			return false
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		cancel(nil)
+		return true
+	}
+	finish := func(val T, err error) {
+		// p 已被拒绝 (例如工厂 Panic) 时不再推进
+		if release() && f.p.GetState() == Pending {
+			f.next(i, val, err)
+		}
+	}
+	stop := context.AfterFunc(stepCtx, func() {
+		finish(*new(T), context.Cause(stepCtx))
+	})
+
+	var src *Promise[T]
+	defer func() {
+		if src == nil && release() {
+			// 工厂 Panic：p 由 handlePanic 拒绝，这里释放本步的定时器与 ctx
+			stop()
+		}
+	}()
+	src = f.factories[i](stepCtx)
+	if src == nil {
+		stop()
+		finish(*new(T), fmt.Errorf("promise: fallback factory %d returned nil", i))
+		return
+	}
+	attachHandler(src, func() {
+		stop()
+		if src.GetState() == Fulfilled {
+			finish(src.val, nil)
+		} else {
+			finish(*new(T), src.err)
+		}
+	})
+}
+
+// next 处理第 i 步的结果：成功则决议，否则按配置决定是否尝试下一个数据源
+func (f *fallback[T]) next(i int, val T, err error) {
+	defer handlePanic(f.p.obs, f.p.Reject)
+
+	if err == nil {
+		f.p.Resolve(val)
+		return
+	}
+	// 外部 Context 结束时不再继续降级
+	if ctxErr := f.ctx.Err(); ctxErr != nil {
+		f.p.Reject(ctxErr)
+		return
+	}
+	if f.opts.ShouldFallback != nil && !f.opts.ShouldFallback(err) {
+		f.p.Reject(err)
+		return
+	}
+	f.errs = append(f.errs, err)
+	f.step(i + 1)
+}
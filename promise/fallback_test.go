@@ -0,0 +1,172 @@
+package promise
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFirstSuccessful_Sequential(t *testing.T) {
+	var calls []string
+	source := func(name string, err error) func(context.Context) *Promise[string] {
+		return func(ctx context.Context) *Promise[string] {
+			calls = append(calls, name)
+			if err != nil {
+				return Reject[string](err)
+			}
+			return Resolve(name)
+		}
+	}
+
+	p := FirstSuccessful(context.Background(),
+		source("cache", errors.New("miss")),
+		source("replica", nil),
+		source("db", nil),
+	)
+
+	val, err := p.Await(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertEqual(t, "replica", val, "first successful source")
+	// 各步依次执行，calls 的写入之间有先后关系，Await 返回后读取是安全的
+	if len(calls) != 2 {
+		t.Errorf("db must not be called, got calls %v", calls)
+	}
+}
+
+func TestFirstSuccessful_AllFail(t *testing.T) {
+	e1, e2 := errors.New("e1"), errors.New("e2")
+	_, err := FirstSuccessful(context.Background(),
+		func(ctx context.Context) *Promise[int] { return Reject[int](e1) },
+		func(ctx context.Context) *Promise[int] { return Reject[int](e2) },
+	).Await(context.Background())
+
+	var agg *AggregateError
+	if !errors.As(err, &agg) {
+		t.Fatalf("expected *AggregateError, got %v", err)
+	}
+	assertEqual(t, 2, len(agg.Errors), "aggregated errors")
+	if !errors.Is(err, e1) || !errors.Is(err, e2) {
+		t.Errorf("aggregate should wrap both errors: %v", agg.Errors)
+	}
+}
+
+func TestFirstSuccessfulWith_StepTimeout(t *testing.T) {
+	slowP := make(chan *Promise[int], 1)
+	slow := func(ctx context.Context) *Promise[int] {
+		p := New(func(resolve func(int), reject func(error)) {
+			select {
+			case <-ctx.Done():
+				reject(ctx.Err())
+			case <-time.After(time.Second):
+				resolve(1)
+			}
+		})
+		slowP <- p
+		return p
+	}
+	fast := func(ctx context.Context) *Promise[int] { return Resolve(2) }
+
+	val, err := FirstSuccessfulWith(context.Background(), FallbackOptions{StepTimeout: 20 * time.Millisecond},
+		slow, fast,
+	).Await(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertEqual(t, 2, val, "fallback after step timeout")
+	// 超时的数据源随单步 Context 结束，等它决议后再返回，避免与后续测试替换调度器竞争
+	if _, err := (<-slowP).Await(context.Background()); !errors.Is(err, context.Canceled) {
+		t.Errorf("slow source should observe step cancellation, got %v", err)
+	}
+}
+
+func TestFirstSuccessfulWith_EventLoop(t *testing.T) {
+	loop := NewEventLoop()
+	setGlobal[TaskDispatcher](t, SetDispatcher, GlobalDispatcher, loop)
+	setGlobal[Clock](t, SetClock, GlobalClock, loop)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = loop.Run(ctx) }()
+
+	// 等待数据源不能阻塞事件循环：数据源与单步定时器都需要事件循环执行
+	never := func(ctx context.Context) *Promise[int] {
+		return New(func(resolve func(int), reject func(error)) {})
+	}
+	deferred := func(ctx context.Context) *Promise[int] {
+		return Resolve(0).Then(func(int) int { return 3 }, nil)
+	}
+	p := FirstSuccessfulWith(ctx, FallbackOptions{StepTimeout: 10 * time.Millisecond}, never, deferred)
+	val, err := p.Timeout(5*time.Second, "fallback stuck").Await(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqual(t, 3, val, "fallback on event loop")
+}
+
+func TestFirstSuccessfulWith_ContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	never := func(ctx context.Context) *Promise[int] {
+		return New(func(resolve func(int), reject func(error)) {})
+	}
+	p := FirstSuccessfulWith(ctx, FallbackOptions{}, never, never)
+	cancel()
+
+	if _, err := p.Await(context.Background()); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestFirstSuccessfulWith_ShouldFallback(t *testing.T) {
+	fatal := errors.New("not found")
+	called := false
+	_, err := FirstSuccessfulWith(context.Background(), FallbackOptions{
+		ShouldFallback: func(err error) bool { return !errors.Is(err, fatal) },
+	},
+		func(ctx context.Context) *Promise[int] { return Reject[int](fatal) },
+		func(ctx context.Context) *Promise[int] { called = true; return Resolve(1) },
+	).Await(context.Background())
+
+	if !errors.Is(err, fatal) {
+		t.Errorf("non-fallthrough error: expected %v, got %v", fatal, err)
+	}
+	if called {
+		t.Error("second source must not be tried")
+	}
+}
+
+func TestFirstSuccessful_NilFactoryResult(t *testing.T) {
+	val, err := FirstSuccessful(context.Background(),
+		func(ctx context.Context) *Promise[int] { return nil },
+		func(ctx context.Context) *Promise[int] { return Resolve(2) },
+	).Await(context.Background())
+	if err != nil {
+		t.Fatalf("nil result should fall through, got %v", err)
+	}
+	assertEqual(t, 2, val, "second source")
+
+	_, err = FirstSuccessful(context.Background(),
+		func(ctx context.Context) *Promise[int] { return nil },
+	).Await(context.Background())
+	var agg *AggregateError
+	if !errors.As(err, &agg) || len(agg.Errors) != 1 {
+		t.Fatalf("expected *AggregateError with one error, got %v", err)
+	}
+}
+
+func TestFirstSuccessfulWith_PanicStopsFallback(t *testing.T) {
+	var called atomic.Int32
+	_, err := FirstSuccessfulWith(context.Background(), FallbackOptions{StepTimeout: 5 * time.Millisecond},
+		func(ctx context.Context) *Promise[int] { panic("boom") },
+		func(ctx context.Context) *Promise[int] { called.Add(1); return Resolve(1) },
+	).Await(context.Background())
+	if err == nil {
+		t.Fatal("expected panic rejection")
+	}
+
+	// 单步定时器到期后也不能在已拒绝的 p 上继续尝试下一个数据源
+	time.Sleep(20 * time.Millisecond)
+	assertEqual(t, int32(0), called.Load(), "second source after panic")
+}
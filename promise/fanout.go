@@ -0,0 +1,84 @@
+package promise
+
+import (
+	"runtime"
+	"sync/atomic"
+)
+
+// -------------------------------------------------------
+// 决议扇出：大量回调切分给多个 worker 并行执行
+// -------------------------------------------------------
+
+// BatchDispatcher 支持批量派发的调度器 (可选接口)
+// 通过 SetFanOutThreshold 开启扇出后，决议时已注册的回调数量达到阈值时，回调被切分成若干批，
+// 第一批在决议方的 Goroutine 上执行，其余批次通过一次 DispatchBatch 提交，每一批应由一个 worker 串行执行。
+// 协程池可借此一次性唤醒多个 worker，避免逐个 Dispatch 的开销。
+type BatchDispatcher interface {
+	TaskDispatcher
+	DispatchBatch(tasks []func())
+}
+
+// minFanOutBatch 每批最少的回调数，避免批次过小时派发开销超过收益
+const minFanOutBatch = 64
+
+// fanOutThreshold 扇出阈值，默认 0 (关闭)
+var fanOutThreshold atomic.Int64
+
+// SetFanOutThreshold 设置决议扇出阈值并开启扇出 (如 256)，默认关闭；n <= 0 关闭扇出
+// 扇出会改变回调的执行语义，因此需要显式开启：
+//   - 回调在批次内保持注册顺序，批次之间并行执行，不再保证整体的 FIFO 顺序
+//   - 除第一批外的回调在其他 worker 上执行，Resolve / Reject 返回时它们可能尚未执行
+func SetFanOutThreshold(n int) {
+	fanOutThreshold.Store(int64(n))
+}
+
+// fanOut 回调数量达到阈值且 d 实现 BatchDispatcher 时按 GOMAXPROCS 切分链表并派发，返回是否已接管执行
+func fanOut(d TaskDispatcher, head *handlerNode) bool {
+	threshold := fanOutThreshold.Load()
+	if threshold <= 0 {
+		return false
+	}
+	bd, ok := d.(BatchDispatcher)
+	if !ok {
+		return false
+	}
+
+	n := 0
+	for node := head; node != nil; node = node.next {
+		n++
+	}
+	if int64(n) < threshold {
+		return false
+	}
+
+	workers := runtime.GOMAXPROCS(0)
+	size := (n + workers - 1) / workers
+	if size < minFanOutBatch {
+		size = minFanOutBatch
+	}
+	if size >= n {
+		return false
+	}
+
+	batches := make([]func(), 0, (n+size-1)/size)
+	for node := head; node != nil; {
+		start := node
+		for i := 1; i < size && node.next != nil; i++ {
+			node = node.next
+		}
+		next := node.next
+		node.next = nil
+		batches = append(batches, func() { runChain(start) })
+		node = next
+	}
+
+	bd.DispatchBatch(batches[1:])
+	batches[0]()
+	return true
+}
+
+// 编译期检查
+var (
+	_ BatchDispatcher = (*defaultDispatcher)(nil)
+	_ Dispatcher      = (*defaultDispatcher)(nil)
+)
@@ -0,0 +1,128 @@
+package promise
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// recordingBatchDispatcher 记录 DispatchBatch 收到的批次数，批次用 Goroutine 执行
+type recordingBatchDispatcher struct {
+	batches atomic.Int32
+}
+
+func (d *recordingBatchDispatcher) Dispatch(f func()) {
+	go f()
+}
+
+func (d *recordingBatchDispatcher) DispatchBatch(tasks []func()) {
+	d.batches.Add(int32(len(tasks)))
+	for _, f := range tasks {
+		go f()
+	}
+}
+
+// setGOMAXPROCS 供 setGlobal 使用 (扇出的切分批次数取决于 GOMAXPROCS)
+func setGOMAXPROCS(n int) { runtime.GOMAXPROCS(n) }
+
+func TestFanOut_SplitsLargeHandlerList(t *testing.T) {
+	d := &recordingBatchDispatcher{}
+	setGlobal[TaskDispatcher](t, SetDispatcher, GlobalDispatcher, d)
+	setGlobal(t, setGOMAXPROCS, runtime.GOMAXPROCS(0), 4)
+	setGlobal(t, SetFanOutThreshold, int(fanOutThreshold.Load()), 256)
+
+	const subscribers = 10000
+	p := &Promise[int]{}
+	var wg sync.WaitGroup
+	var sum atomic.Int64
+	wg.Add(subscribers)
+	for i := 0; i < subscribers; i++ {
+		p.Finally(func() {
+			sum.Add(1)
+			wg.Done()
+		})
+	}
+
+	p.Resolve(1)
+	wg.Wait()
+
+	if got := sum.Load(); got != subscribers {
+		t.Fatalf("ran %d handlers, want %d", got, subscribers)
+	}
+	// 4 个 worker：第一批在决议方执行，其余 3 批批量派发
+	if got := d.batches.Load(); got != 3 {
+		t.Fatalf("DispatchBatch got %d batches, want 3", got)
+	}
+}
+
+func TestFanOut_BelowThresholdKeepsOrder(t *testing.T) {
+	d := &recordingBatchDispatcher{}
+	setGlobal[TaskDispatcher](t, SetDispatcher, GlobalDispatcher, d)
+	setGlobal(t, setGOMAXPROCS, runtime.GOMAXPROCS(0), 4)
+	setGlobal(t, SetFanOutThreshold, int(fanOutThreshold.Load()), 256)
+
+	p := &Promise[int]{}
+	var order []int
+	for i := 0; i < 255; i++ {
+		i := i
+		p.pushHandler(func() { order = append(order, i) })
+	}
+	p.Resolve(1)
+
+	if d.batches.Load() != 0 {
+		t.Fatal("handler list below threshold should not be batched")
+	}
+	for i, v := range order {
+		if v != i {
+			t.Fatalf("order[%d] = %d, handlers must run in FIFO order", i, v)
+		}
+	}
+}
+
+func TestFanOut_DisabledByDefault(t *testing.T) {
+	// 未开启扇出时，即使调度器实现了 BatchDispatcher，回调也按注册顺序在决议方执行完毕后 Resolve 才返回
+	for _, d := range []TaskDispatcher{&defaultDispatcher{}, &recordingBatchDispatcher{}} {
+		prev := GlobalDispatcher
+		SetDispatcher(d)
+
+		p := &Promise[int]{}
+		var order []int
+		for i := 0; i < 1000; i++ {
+			i := i
+			p.pushHandler(func() { order = append(order, i) })
+		}
+		p.Resolve(1)
+		SetDispatcher(prev)
+
+		if len(order) != 1000 {
+			t.Fatalf("%T: Resolve returned after %d of 1000 handlers", d, len(order))
+		}
+		for i, v := range order {
+			if v != i {
+				t.Fatalf("%T: order[%d] = %d, handlers must run in FIFO order", d, i, v)
+			}
+		}
+		if rd, ok := d.(*recordingBatchDispatcher); ok && rd.batches.Load() != 0 {
+			t.Fatalf("fan-out must be opt-in, got %d batches", rd.batches.Load())
+		}
+	}
+}
+
+func TestFanOut_Disabled(t *testing.T) {
+	d := &recordingBatchDispatcher{}
+	setGlobal[TaskDispatcher](t, SetDispatcher, GlobalDispatcher, d)
+	setGlobal(t, setGOMAXPROCS, runtime.GOMAXPROCS(0), 4)
+	setGlobal(t, SetFanOutThreshold, int(fanOutThreshold.Load()), 0)
+
+	p := &Promise[int]{}
+	n := 0
+	for i := 0; i < 1000; i++ {
+		p.pushHandler(func() { n++ })
+	}
+	p.Resolve(1)
+
+	if n != 1000 || d.batches.Load() != 0 {
+		t.Fatalf("ran %d handlers with %d batches, want 1000 serial", n, d.batches.Load())
+	}
+}
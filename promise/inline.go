@@ -0,0 +1,24 @@
+package promise
+
+// -------------------------------------------------------
+// 内联执行：廉价的回调直接在决议方的 Goroutine 上执行
+// -------------------------------------------------------
+
+// maxInlineDepth 连续内联执行的最大跳数，超过后派发到 GlobalDispatcher 以重置调用栈
+const maxInlineDepth = 128
+
+// WithInline 让 Promise 及其派生的 Then / Catch / Finally 链路以内联模式执行回调，等价于链路上每一跳都使用 ThenSync
+// 适合纯计算的短回调；回调中有阻塞操作时会阻塞决议方 (或调用 Then 的 Goroutine)，此时应使用默认模式
+func WithInline() Option {
+	return func(o *options) {
+		o.inline = true
+	}
+}
+
+// ThenSync 与 Then 相同，但回调不经过 GlobalDispatcher：
+// p 已决议时在当前 Goroutine 上直接执行，否则在决议 p 的 Goroutine 上执行。
+// 每一跳都省去一次任务派发 (默认调度器下即一个 Goroutine)；连续内联超过 128 跳时自动退回派发，避免栈溢出。
+// 只影响这一跳，返回的 Promise 沿用 p 的执行模式。
+func (p *Promise[T]) ThenSync(onFulfilled func(T) T, onRejected func(error) error) *Promise[T] {
+	return p.then("ThenSync", onFulfilled, onRejected, true)
+}
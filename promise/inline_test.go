@@ -0,0 +1,94 @@
+package promise
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// countingDispatcher 统计派发次数，任务仍在新的 Goroutine 中执行
+type countingDispatcher struct {
+	n atomic.Int32
+}
+
+func (d *countingDispatcher) Dispatch(f func()) {
+	d.n.Add(1)
+	go f()
+}
+
+func TestThenSync_SettledRunsImmediately(t *testing.T) {
+	d := &countingDispatcher{}
+	setGlobal[TaskDispatcher](t, SetDispatcher, GlobalDispatcher, d)
+
+	child := Resolve(1).ThenSync(func(v int) int { return v + 1 }, nil)
+	if child.GetState() != Fulfilled || child.val != 2 {
+		t.Fatalf("ThenSync on settled promise should run synchronously, got %v", child)
+	}
+	// 只影响这一跳
+	next := child.Then(func(v int) int { return v }, nil)
+	if _, err := next.Await(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := d.n.Load(); n != 1 {
+		t.Errorf("expected exactly 1 dispatch (the plain Then), got %d", n)
+	}
+}
+
+func TestWithInline_Chain(t *testing.T) {
+	gate := make(chan struct{})
+	root := New(func(resolve func(int), reject func(error)) {
+		<-gate
+		resolve(1)
+	}, WithInline())
+
+	d := &countingDispatcher{}
+	setGlobal[TaskDispatcher](t, SetDispatcher, GlobalDispatcher, d)
+	tail := root.Then(func(v int) int { return v * 10 }, nil).
+		Finally(func() {}).
+		Catch(func(err error) error { return err })
+	close(gate)
+
+	v, err := tail.Await(context.Background())
+	if err != nil || v != 10 {
+		t.Fatalf("expected 10, got %v, %v", v, err)
+	}
+	if n := d.n.Load(); n != 0 {
+		t.Errorf("inline chain should not dispatch, got %d", n)
+	}
+}
+
+func TestThenSync_DepthGuard(t *testing.T) {
+	d := &countingDispatcher{}
+	setGlobal[TaskDispatcher](t, SetDispatcher, GlobalDispatcher, d)
+
+	root := &Promise[int]{}
+	const hops = 1000
+	p := root
+	for i := 0; i < hops; i++ {
+		p = p.ThenSync(func(v int) int { return v + 1 }, nil)
+	}
+	root.Resolve(0)
+
+	v, err := p.Await(context.Background())
+	if err != nil || v != hops {
+		t.Fatalf("expected %d, got %v, %v", hops, v, err)
+	}
+	if n, want := d.n.Load(), int32(hops/(maxInlineDepth+1)); n != want {
+		t.Errorf("expected %d fallback dispatches, got %d", want, n)
+	}
+}
+
+func TestThenSync_Panic(t *testing.T) {
+	child := Resolve(1).ThenSync(func(v int) int { panic("boom") }, nil)
+	_, err := child.Await(context.Background())
+	if err == nil || !strings.HasPrefix(err.Error(), "panic: boom") {
+		t.Fatalf("expected panic rejection, got %v", err)
+	}
+
+	rejected := Reject[int](errors.New("x")).ThenSync(nil, func(err error) error { return errors.New("y") })
+	if _, err := rejected.Await(context.Background()); err == nil || !strings.HasPrefix(err.Error(), "y") {
+		t.Fatalf("expected y, got %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package promise
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Metrics 指标钩子接口
+// 通过 SetMetrics 设置后，库在 Promise 创建、决议、Panic、回调执行以及任务派发时调用对应方法。
+// 基于 expvar 的默认实现和 Prometheus 文本格式导出见 promisemetrics 子包。
+//
+// 与 Tracer 相同，遵循包文档「钩子」一节的调用约定。
+type Metrics interface {
+	// PromiseCreated Promise 创建 (op 如 "New"、"Then"、"All")
+	PromiseCreated(op string)
+	// PromiseSettled Promise 决议，elapsed 为从创建到决议的耗时
+	// 上报给 Promise 创建时设置的实例 (即使其间 SetMetrics 替换了实例)，保证与同一实例上的 PromiseCreated 成对出现
+	PromiseSettled(op string, state State, elapsed time.Duration)
+	// PromisePanicked executor 或回调发生 Panic (随后会以 Rejected 决议)
+	PromisePanicked()
+	// HandlerDone 用户回调 (executor / Then / Finally 回调) 执行完毕
+	HandlerDone(op string, d time.Duration)
+	// TaskQueued 任务提交到 GlobalDispatcher
+	TaskQueued()
+	// TaskStarted 已提交的任务开始执行 (TaskQueued - TaskStarted 即调度器积压量)
+	TaskStarted()
+}
+
+// NopMetrics 空实现，可嵌入自定义 Metrics 以只实现部分钩子
+type NopMetrics struct{}
+
+func (NopMetrics) PromiseCreated(string)                       {}
+func (NopMetrics) PromiseSettled(string, State, time.Duration) {}
+func (NopMetrics) PromisePanicked()                            {}
+func (NopMetrics) HandlerDone(string, time.Duration)           {}
+func (NopMetrics) TaskQueued()                                 {}
+func (NopMetrics) TaskStarted()                                {}
+
+type metricsBox struct {
+	m Metrics
+}
+
+var globalMetrics atomic.Pointer[metricsBox]
+
+// SetMetrics 设置全局 Metrics，传入 nil 关闭指标采集
+// 已创建的 Promise 仍向创建时的实例上报决议事件
+func SetMetrics(m Metrics) {
+	if m == nil {
+		globalMetrics.Store(nil)
+		return
+	}
+	globalMetrics.Store(&metricsBox{m: m})
+}
+
+func loadMetrics() Metrics {
+	if b := globalMetrics.Load(); b != nil {
+		return b.m
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package promise
+
+import (
+	"context"
+	"runtime/pprof"
+	"sync/atomic"
+	"time"
+)
+
+// -------------------------------------------------------
+// 可观测性元数据 (Tracer / Metrics / 调试模式共用)
+// -------------------------------------------------------
+
+// observation 单个 Promise 的可观测性元数据
+// 仅在创建时开启了任一钩子 (或调试模式、或设置了名称) 才分配，未开启时 Promise.obs 为 nil，热路径只多一次指针判断
+// 除原子计数外创建后只读，可在任意 Goroutine 中安全访问
+type observation struct {
+	id      uint64
+	op      string
+	name    string
+	created time.Time
+	site    CallSite // 创建位置，仅调试模式或异步调用栈模式下记录
+	metrics Metrics  // 创建时的 Metrics，决议时上报给同一实例 (保证 pending 计数成对)
+
+	labels   []string // pprof 标签键值对，仅开启 SetProfilerLabels 时记录
+	labelSet pprof.LabelSet
+
+	handlers atomic.Int32 // 已挂载的回调数量 (供 Pending 注册表展示)
+}
+
+var promiseIDSeed atomic.Uint64
+
+// ID 返回 Promise 的追踪 ID，未分配元数据的 Promise 返回 0
+func (p *Promise[T]) ID() uint64 {
+	if p.obs == nil {
+		return 0
+	}
+	return p.obs.id
+}
+
+// observing 是否需要为新建的 Promise 分配元数据
+func observing() bool {
+	return globalTracer.Load() != nil || globalMetrics.Load() != nil || debugEnabled.Load() ||
+		registryEnabled.Load() || asyncTraceEnabled.Load() || globalObservers.Load() != nil ||
+		profilerLabelsEnabled.Load()
+}
+
+// newObservation 分配元数据并上报创建事件
+func newObservation(ctx context.Context, op, name string, parents []uint64) *observation {
+	t, m := loadTracer(), loadMetrics()
+
+	o := &observation{
+		id:      promiseIDSeed.Add(1),
+		op:      op,
+		name:    name,
+		created: time.Now(),
+		metrics: m,
+	}
+	if debugEnabled.Load() || asyncTraceEnabled.Load() {
+		o.site = captureSite()
+	}
+	if t != nil {
+		t.OnCreate(CreateEvent{Ctx: ctx, ID: o.id, Op: op, Name: name, Parents: parents, Time: o.created})
+	}
+	if m != nil {
+		m.PromiseCreated(op)
+	}
+	return o
+}
+
+// observe 为新建的 Promise 挂载元数据，parent 为上游 Promise 的元数据 (可为 nil)
+func (p *Promise[T]) observe(ctx context.Context, op string, parent *observation) {
+	p.observeNamed(ctx, op, parent, "")
+}
+
+// observeNamed 带名称的 observe，设置了名称时总会分配元数据
+func (p *Promise[T]) observeNamed(ctx context.Context, op string, parent *observation, name string) {
+	if name == "" && !observing() {
+		return
+	}
+	var parents []uint64
+	if parent != nil {
+		parents = []uint64{parent.id}
+	}
+	p.obs = newObservation(ctx, op, name, parents)
+	p.obs.setLabels(profileLabels(ctx, op, name, parent))
+	p.register()
+}
+
+// observeJoin 聚合类操作的 observe，所有输入 Promise 均为上游
+func observeJoin[T any, R any](p *Promise[R], op string, inputs []*Promise[T]) {
+	if !observing() {
+		return
+	}
+	parents := make([]uint64, 0, len(inputs))
+	for _, in := range inputs {
+		if in.obs != nil {
+			parents = append(parents, in.obs.id)
+		}
+	}
+	p.obs = newObservation(nil, op, "", parents)
+	p.obs.setLabels(profileLabels(nil, op, "", nil))
+	p.register()
+}
+
+func (o *observation) setLabels(kv []string) {
+	if kv != nil {
+		o.labels = kv
+		o.labelSet = pprof.Labels(kv...)
+	}
+}
+
+// observeSettle 上报决议事件 (Resolve/Reject 已完成状态切换后调用)
+// propagated 表示拒绝原因来自上游 Promise
+func (p *Promise[T]) observeSettle(propagated bool) {
+	o := p.obs
+	if o == nil {
+		return
+	}
+	p.unregister()
+	now := time.Now()
+	state := p.GetState()
+	if t := loadTracer(); t != nil {
+		t.OnSettle(SettleEvent{ID: o.id, State: state, Err: p.err, Time: now, Propagated: propagated})
+	}
+	if obs := loadObservers(); obs != nil {
+		info := SettleInfo{Info: o.info(state), Err: p.err, Duration: now.Sub(o.created), Propagated: propagated}
+		for _, ob := range obs {
+			ob.o.OnSettled(info)
+		}
+	}
+	if o.metrics != nil {
+		o.metrics.PromiseSettled(o.op, state, now.Sub(o.created))
+	}
+}
+
+// handlerRun 上报回调执行耗时，配合 defer 使用
+// executor 内部自行调用 resolve，因此其 HandlerEvent 可能晚于同一 Promise 的 SettleEvent
+func (o *observation) handlerRun(op string, start time.Time) {
+	d := time.Since(start)
+	if t := loadTracer(); t != nil {
+		t.OnHandlerRun(HandlerEvent{ID: o.id, Op: op, Start: start, Duration: d})
+	}
+	if m := loadMetrics(); m != nil {
+		m.HandlerDone(op, d)
+	}
+}
+
+// handlerStart 记录 Then / Finally 回调的开始时间 (未开启钩子时不读取时钟)
+func (p *Promise[T]) handlerStart() time.Time {
+	if p.obs == nil {
+		return time.Time{}
+	}
+	return time.Now()
+}
+
+// handlerDone 在决议子 Promise 之前上报回调执行事件
+func (p *Promise[T]) handlerDone(op string, start time.Time) {
+	if p.obs != nil {
+		p.obs.handlerRun(op, start)
+	}
+}
+
+// dispatch 通过 GlobalDispatcher 派发任务 (executor 等宏任务)，o 为任务所属 Promise 的元数据，prio 为其优先级
+// GlobalDispatcher 实现 Dispatcher 时可能拒绝任务 (如已关闭)，此时返回错误且 f 不会执行，由调用方拒绝对应的 Promise
+func dispatch(ctx context.Context, o *observation, prio Priority, f func()) error {
+	return dispatchTask(ctx, o, prio, f, false)
+}
+
+// dispatchReaction 派发 Promise 反应 (Then / Finally / Map 的回调)
+// GlobalDispatcher 实现 MicrotaskDispatcher 时作为微任务派发
+// 反应总是被接受：调度器关闭 (Drain) 期间已有链路仍能执行完毕
+func dispatchReaction(o *observation, prio Priority, f func()) {
+	_ = dispatchTask(context.Background(), o, prio, f, true)
+}
+
+func dispatchTask(ctx context.Context, o *observation, prio Priority, f func(), micro bool) error {
+	if o != nil {
+		if t := loadTracer(); t != nil {
+			t.OnDispatch(DispatchEvent{ID: o.id, Time: time.Now()})
+		}
+		if o.labels != nil {
+			f = withProfileLabels(o, f)
+		}
+	}
+	m := loadMetrics()
+	if m != nil {
+		m.TaskQueued()
+		inner := f
+		f = func() {
+			m.TaskStarted()
+			inner()
+		}
+	}
+	d := GlobalDispatcher
+	if !micro {
+		if sd, ok := d.(Dispatcher); ok {
+			err := sd.DispatchTask(Task{Ctx: ctx, Priority: prio, Run: f})
+			if err != nil && m != nil {
+				// 被拒绝的任务视为已出队，保持积压量平衡
+				m.TaskStarted()
+			}
+			return err
+		}
+	}
+	if md, ok := d.(MicrotaskDispatcher); ok && micro {
+		md.DispatchMicrotask(f)
+		return nil
+	}
+	if pd, ok := d.(PriorityDispatcher); ok {
+		pd.DispatchPriority(prio, f)
+		return nil
+	}
+	d.Dispatch(f)
+	return nil
+}
@@ -0,0 +1,115 @@
+package promise
+
+import (
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// -------------------------------------------------------
+// 生命周期观察者 (日志等场景)
+// -------------------------------------------------------
+
+// Observer Promise 生命周期观察者
+// 通过 AddObserver 注册后，库在 Promise 决议以及 executor / 回调发生 Panic 时调用对应方法。
+// 基于 log/slog 的实现见 SlogObserver。
+//
+// 调用约定同 Tracer / Metrics，见包文档「钩子」一节。
+type Observer interface {
+	// OnSettled Promise 决议 (只有在注册观察者之后创建的 Promise 才会上报)
+	OnSettled(SettleInfo)
+	// OnPanic executor 或回调发生 Panic (随后所属 Promise 会以 Rejected 决议)
+	OnPanic(PanicInfo)
+}
+
+// SettleInfo 决议事件
+type SettleInfo struct {
+	Info
+	Err error
+	// Duration 从创建到决议的耗时
+	Duration time.Duration
+	// Propagated 拒绝原因来自上游 Promise (沿 Then / Finally / 聚合等链路传播)，而非在此处产生
+	Propagated bool
+}
+
+// PanicInfo Panic 事件
+type PanicInfo struct {
+	// Info 发生 Panic 的回调所属 Promise，未分配元数据时只有 State
+	Info
+	Value interface{}
+	Stack []byte
+}
+
+var (
+	observersMu sync.Mutex
+	// globalObservers 写时复制，热路径只需一次原子读取
+	globalObservers atomic.Pointer[[]*observerEntry]
+)
+
+// observerEntry 以指针区分每次注册，同一个观察者可重复注册，且不要求实现类型可比较
+type observerEntry struct {
+	o Observer
+}
+
+// AddObserver 注册观察者，返回的 remove 函数用于注销 (可重复调用)
+func AddObserver(o Observer) (remove func()) {
+	observersMu.Lock()
+	defer observersMu.Unlock()
+
+	e := &observerEntry{o: o}
+	var list []*observerEntry
+	if cur := globalObservers.Load(); cur != nil {
+		list = append(list, *cur...)
+	}
+	list = append(list, e)
+	globalObservers.Store(&list)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { removeObserver(e) })
+	}
+}
+
+func removeObserver(e *observerEntry) {
+	observersMu.Lock()
+	defer observersMu.Unlock()
+
+	cur := globalObservers.Load()
+	if cur == nil {
+		return
+	}
+	list := make([]*observerEntry, 0, len(*cur))
+	for _, ob := range *cur {
+		if ob != e {
+			list = append(list, ob)
+		}
+	}
+	if len(list) == 0 {
+		globalObservers.Store(nil)
+		return
+	}
+	globalObservers.Store(&list)
+}
+
+func loadObservers() []*observerEntry {
+	if list := globalObservers.Load(); list != nil {
+		return *list
+	}
+	return nil
+}
+
+// notifyPanic 通知观察者发生 Panic，须在 recover 所在的 defer 中调用以便获取 Panic 现场的调用栈
+func notifyPanic(o *observation, value interface{}) {
+	obs := loadObservers()
+	if obs == nil {
+		return
+	}
+	info := PanicInfo{Info: Info{State: Pending}, Value: value, Stack: debug.Stack()}
+	if o != nil {
+		info.Info = o.info(Pending)
+	}
+	for _, ob := range obs {
+		ob.o.OnPanic(info)
+	}
+}
@@ -0,0 +1,142 @@
+package promise
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// -------------------------------------------------------
+// Promise 对象池化 (显式 Release)
+// -------------------------------------------------------
+
+// errUseAfterRelease 调试模式下使用已 Release 的 Pooled Promise 时的 panic 值
+var errUseAfterRelease = errors.New("promise: use of Pooled promise after Release")
+
+// Pooled 可复用的 Promise，由 PromisePool 分配，嵌入 Promise 因此可直接调用 Then / Await 等方法
+// 最后一个使用方调用 Release 把它归还对象池。
+//
+// 警告：Release 之后不能再以任何方式访问它，否则会读写到下一次分配的结果，且通常不会报错：
+//   - 直接调用 p.Resolve / p.Reject (Get 的用法) 没有任何保护，迟到的调用会决议下一次分配出去的 Promise；
+//     PromisePool.New 传给 executor 的 resolve / reject 带有代数检查，Release 之后的调用会被忽略
+//   - 通过调度器派发的回调 (如在已决议的 p 上调用 Then、EventLoop 的微任务、扇出到其他 worker 的批次)
+//     不在 Release 的等待范围内，必须在它们执行完之后再 Release
+//
+// 开启调试模式 (SetDebug 或 -tags promisedebug) 时，Release 后的对象不会被复用，之后的使用会 panic。
+type Pooled[T any] struct {
+	Promise[T]
+	pool     *PromisePool[T]
+	released atomic.Bool
+	gen      atomic.Uint32 // 每次 Release 加一，见 PromisePool.New
+}
+
+// PromisePool 某一类型 Pooled Promise 的对象池，零值可直接使用
+// 适合每秒创建海量短命 Promise 的热路径，其他场景使用普通的 New 即可。
+type PromisePool[T any] struct {
+	pool sync.Pool
+}
+
+// NewPromisePool 创建对象池
+func NewPromisePool[T any]() *PromisePool[T] {
+	return &PromisePool[T]{}
+}
+
+// Get 取出一个 Pending 的 Promise，由调用方通过 Resolve / Reject 决议
+func (pp *PromisePool[T]) Get() *Pooled[T] {
+	p, _ := pp.pool.Get().(*Pooled[T])
+	if p == nil {
+		p = &Pooled[T]{pool: pp}
+	}
+	p.released.Store(false)
+	p.observe(nil, "Pooled", nil)
+	return p
+}
+
+// New 与包级 New 相同：取出一个 Promise 并通过调度器异步执行 executor
+// 传给 executor 的 resolve / reject 绑定本次分配：Release 之后 (对象可能已被再次分配) 的调用会被忽略，调试模式下 panic。
+func (pp *PromisePool[T]) New(executor func(resolve func(T), reject func(error))) *Pooled[T] {
+	p := pp.Get()
+	r := &pooledResolver[T]{p: p, gen: p.gen.Load()}
+	// 与 Promise.run 相同，只是把 executor 拿到的 resolve / reject 换成 r 的方法
+	err := dispatch(context.Background(), p.obs, p.priority, func() {
+		defer handlePanic(p.obs, p.Reject)
+		if p.obs != nil {
+			defer p.obs.handlerRun("Pooled", time.Now())
+		}
+		executor(r.resolve, r.reject)
+	})
+	if err != nil {
+		p.Reject(err)
+	}
+	return p
+}
+
+// pooledResolver 绑定一次分配的代数，PromisePool.New 把它的方法交给 executor
+// 每次分配只多出这一个对象和它的两个方法值，不必为 resolve / reject 各包一层闭包。
+type pooledResolver[T any] struct {
+	p   *Pooled[T]
+	gen uint32
+}
+
+func (r *pooledResolver[T]) resolve(v T) {
+	if r.p.gen.Load() != r.gen {
+		r.p.checkReleased()
+		return
+	}
+	r.p.Resolve(v)
+}
+
+func (r *pooledResolver[T]) reject(err error) {
+	if r.p.gen.Load() != r.gen {
+		r.p.checkReleased()
+		return
+	}
+	r.p.Reject(err)
+}
+
+// Release 归还对象池，只能在 Promise 决议且所有使用方都已读取结果之后调用
+// 决议方仍在执行已注册的回调时，Release 会等待它们执行完毕，因此不能在 p 自身的回调中调用 (会死锁)。
+// 对 Pending 的 Promise 或重复调用 Release 会 panic。
+func (p *Pooled[T]) Release() {
+	s := atomic.LoadUint32(&p.state)
+	if s == released || p.released.Load() {
+		panic(errUseAfterRelease)
+	}
+	if s != uint32(Fulfilled) && s != uint32(Rejected) {
+		panic("promise: Release of unsettled Pooled promise")
+	}
+	if !p.released.CompareAndSwap(false, true) {
+		panic(errUseAfterRelease)
+	}
+	// Await 在决议方发布状态后即可返回，此时决议方可能仍在上报决议事件、执行回调，等它结束再回收
+	p.waitSettled()
+	p.gen.Add(1)
+
+	if debugEnabled.Load() {
+		// 调试模式下毒化并丢弃，让之后的使用可被检测到
+		atomic.StoreUint32(&p.state, released)
+		return
+	}
+
+	var zero T
+	p.val = zero
+	p.err = nil
+	p.handlers, p.handlersTail = nil, nil
+	p.depth = 0
+	p.inline = false
+	p.priority = PriorityNormal
+	p.obs = nil
+	p.settled.Store(settleRunning)
+	p.shared.Store(nil)
+	atomic.StoreUint32(&p.state, uint32(Pending))
+	p.pool.pool.Put(p)
+}
+
+// checkReleased 调试模式下检测对已 Release 的 Pooled Promise 的使用
+func (p *Promise[T]) checkReleased() {
+	if atomic.LoadUint32(&p.state) == released {
+		panic(errUseAfterRelease)
+	}
+}
@@ -0,0 +1,163 @@
+package promise
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func expectPanic(t *testing.T, want interface{}, f func()) {
+	t.Helper()
+	defer func() {
+		if r := recover(); r != want {
+			t.Fatalf("panic = %v, want %v", r, want)
+		}
+	}()
+	f()
+}
+
+func TestPooled_ReuseAfterRelease(t *testing.T) {
+	pool := NewPromisePool[int]()
+
+	p := pool.New(func(resolve func(int), reject func(error)) { resolve(1) })
+	if v, err := p.Await(context.Background()); err != nil || v != 1 {
+		t.Fatalf("Await = %v, %v", v, err)
+	}
+	p.Release()
+
+	// 复用的对象必须是干净的 Pending 状态
+	q := pool.Get()
+	if q.GetState() != Pending {
+		t.Fatalf("reused promise state = %v, want pending", q.GetState())
+	}
+	boom := errors.New("boom")
+	q.Reject(boom)
+	if _, err := q.Await(context.Background()); !errors.Is(err, boom) {
+		t.Fatalf("Await err = %v, want boom", err)
+	}
+	q.Release()
+}
+
+func TestPooled_ReleasePendingPanics(t *testing.T) {
+	p := NewPromisePool[int]().Get()
+	expectPanic(t, "promise: Release of unsettled Pooled promise", p.Release)
+}
+
+func TestPooled_DoubleReleasePanics(t *testing.T) {
+	p := NewPromisePool[int]().Get()
+	p.Resolve(1)
+	p.Release()
+	expectPanic(t, errUseAfterRelease, p.Release)
+}
+
+func TestPooled_DebugDetectsUseAfterRelease(t *testing.T) {
+	setGlobal(t, SetDebug, DebugEnabled(), true)
+
+	pool := NewPromisePool[int]()
+	p := pool.Get()
+	p.Resolve(1)
+	p.Release()
+
+	expectPanic(t, errUseAfterRelease, func() { p.Then(func(v int) int { return v }, nil) })
+	expectPanic(t, errUseAfterRelease, func() { _, _ = p.Await(context.Background()) })
+	expectPanic(t, errUseAfterRelease, func() { p.Resolve(2) })
+
+	// 调试模式下已 Release 的对象不会被复用
+	if q := pool.Get(); q == p {
+		t.Fatal("released promise was reused in debug mode")
+	}
+}
+
+func TestPooled_StaleResolveIgnored(t *testing.T) {
+	setGlobal(t, SetDebug, DebugEnabled(), false)
+
+	pool := NewPromisePool[int]()
+	var stale func(int)
+	p := pool.New(func(resolve func(int), reject func(error)) {
+		stale = resolve
+		resolve(1)
+	})
+	if _, err := p.Await(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	p.Release()
+
+	// Release 之后对象已重置为 Pending (可能被再次分配)，迟到的 resolve 不能决议它
+	stale(2)
+	if s := p.GetState(); s != Pending {
+		t.Fatalf("stale resolve settled the recycled promise: %v", s)
+	}
+}
+
+func TestPooled_StaleResolvePanicsInDebug(t *testing.T) {
+	setGlobal(t, SetDebug, DebugEnabled(), true)
+
+	var stale func(int)
+	p := NewPromisePool[int]().New(func(resolve func(int), reject func(error)) {
+		stale = resolve
+		resolve(1)
+	})
+	if _, err := p.Await(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	p.Release()
+	expectPanic(t, errUseAfterRelease, func() { stale(2) })
+}
+
+func TestPooled_ReleaseWaitsForHandlers(t *testing.T) {
+	p := NewPromisePool[int]().Get()
+	running, unblock := make(chan struct{}), make(chan struct{})
+	var seen int
+	p.pushHandler(func() {
+		close(running)
+		<-unblock
+		seen = p.val // 回调仍在读取 p 的字段
+	})
+	go p.Resolve(1)
+	<-running
+
+	released := make(chan struct{})
+	go func() {
+		p.Release()
+		close(released)
+	}()
+	select {
+	case <-released:
+		t.Fatal("Release returned while a handler was still running")
+	case <-time.After(10 * time.Millisecond):
+	}
+	close(unblock)
+	<-released
+	if seen != 1 {
+		t.Fatalf("handler read %d after Release, want 1", seen)
+	}
+}
+
+// syncDispatcher 在调用方 Goroutine 中直接执行任务，使分配次数可以稳定测量
+type syncDispatcher struct{}
+
+func (syncDispatcher) Dispatch(f func()) { f() }
+
+func TestPooled_NewAllocatesLessThanNew(t *testing.T) {
+	if DebugEnabled() {
+		t.Skip("调试模式下 Release 的对象不会被复用")
+	}
+	setGlobal[TaskDispatcher](t, SetDispatcher, GlobalDispatcher, syncDispatcher{})
+
+	ctx := context.Background()
+	executor := func(resolve func(int), reject func(error)) { resolve(1) }
+	plain := testing.AllocsPerRun(100, func() {
+		_, _ = New(executor).Await(ctx)
+	})
+	pool := NewPromisePool[int]()
+	pooled := testing.AllocsPerRun(100, func() {
+		p := pool.New(executor)
+		_, _ = p.Await(ctx)
+		p.Release()
+	})
+	if pooled >= plain {
+		t.Errorf("PromisePool.New allocs/op = %v, want fewer than New (%v)", pooled, plain)
+	}
+	t.Logf("allocs/op: New %v, PromisePool.New %v", plain, pooled)
+}
@@ -0,0 +1,92 @@
+package promise
+
+import (
+	"context"
+	"runtime/pprof"
+	"sync/atomic"
+)
+
+// -------------------------------------------------------
+// pprof 标签：让 CPU / Goroutine Profile 按逻辑操作归因
+// -------------------------------------------------------
+
+const (
+	// LabelOp pprof 标签键：创建 Promise 的操作 (如 "New"、"Then"、"All")
+	LabelOp = "promise.op"
+	// LabelName pprof 标签键：Promise 名称 (WithName)，Then / Map 等下游 Promise 未命名时沿用上游名称
+	LabelName = "promise.name"
+)
+
+// profilerLabelsEnabled pprof 标签开关，见 SetProfilerLabels
+var profilerLabelsEnabled atomic.Bool
+
+// SetProfilerLabels 开启/关闭 pprof 标签
+// 开启后通过 GlobalDispatcher 派发的 executor 与回调都在 pprof.Do 中执行，携带以下标签：
+//   - LabelOp / LabelName
+//   - NewWithContext / FirstSuccessfulWith / RateLimited 传入的 ctx 上已有的 pprof 标签 (如调用方通过 pprof.Do 设置的)
+//
+// Then / Map / Finally / Timeout 继承上游 Promise 的标签，因此整条链路的耗时都会归到同一个逻辑操作上。
+// 只对开启之后创建的 Promise 生效。
+func SetProfilerLabels(on bool) {
+	profilerLabelsEnabled.Store(on)
+}
+
+// profileLabels 计算 Promise 的 pprof 标签 (键值对交替排列)，未开启时返回 nil
+// 优先级：自身的 op/name > 上游 Promise 的标签 > ctx 上的标签
+func profileLabels(ctx context.Context, op, name string, parent *observation) []string {
+	if !profilerLabelsEnabled.Load() {
+		return nil
+	}
+
+	var kv []string
+	if parent != nil && parent.labels != nil {
+		kv = append(kv, parent.labels...)
+	} else if ctx != nil {
+		pprof.ForLabels(ctx, func(k, v string) bool {
+			kv = append(kv, k, v)
+			return true
+		})
+	}
+	kv = setLabel(kv, LabelOp, op)
+	if name != "" {
+		kv = setLabel(kv, LabelName, name)
+	}
+	return kv
+}
+
+// setLabel 设置或覆盖键值对中的一个标签，总是返回新切片 (上游的标签切片是共享的)
+func setLabel(kv []string, key, value string) []string {
+	out := make([]string, 0, len(kv)+2)
+	found := false
+	for i := 0; i+1 < len(kv); i += 2 {
+		if kv[i] == key {
+			out = append(out, key, value)
+			found = true
+			continue
+		}
+		out = append(out, kv[i], kv[i+1])
+	}
+	if !found {
+		out = append(out, key, value)
+	}
+	return out
+}
+
+// labeled 为挂载在 Pending Promise 上的回调附加标签
+// 这类回调在决议方的 Goroutine 上直接执行 (不经过 dispatch)，否则会被归到上游的标签下
+func labeled(o *observation, f func()) func() {
+	if o == nil || o.labels == nil {
+		return f
+	}
+	return withProfileLabels(o, f)
+}
+
+// withProfileLabels 让 f 在携带 Promise 标签的 pprof.Do 中执行
+func withProfileLabels(o *observation, f func()) func() {
+	set := o.labelSet
+	return func() {
+		pprof.Do(context.Background(), set, func(context.Context) {
+			f()
+		})
+	}
+}
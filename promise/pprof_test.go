@@ -0,0 +1,62 @@
+package promise
+
+import (
+	"bytes"
+	"context"
+	"runtime/pprof"
+	"strings"
+	"testing"
+)
+
+// goroutineProfile 返回 debug=1 格式的 Goroutine Profile，其中包含各 Goroutine 的 pprof 标签
+func goroutineProfile(t *testing.T) string {
+	var buf bytes.Buffer
+	if err := pprof.Lookup("goroutine").WriteTo(&buf, 1); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestProfilerLabels_ExecutorAndHandler(t *testing.T) {
+	setGlobal(t, SetProfilerLabels, profilerLabelsEnabled.Load(), true)
+
+	var inExecutor, inHandler string
+	ctx := pprof.WithLabels(context.Background(), pprof.Labels("request", "r-42"))
+	p := NewWithContext(ctx, func(resolve func(int), reject func(error)) {
+		inExecutor = goroutineProfile(t)
+		resolve(1)
+	}, WithName("load-user"))
+	child := p.Then(func(v int) int {
+		inHandler = goroutineProfile(t)
+		return v
+	}, nil)
+	if _, err := child.Await(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{`"promise.name":"load-user"`, `"promise.op":"NewWithContext"`, `"request":"r-42"`} {
+		if !strings.Contains(inExecutor, want) {
+			t.Errorf("executor labels missing %s", want)
+		}
+	}
+	// Then 继承上游的名称与 ctx 标签
+	for _, want := range []string{`"promise.name":"load-user"`, `"promise.op":"Then"`, `"request":"r-42"`} {
+		if !strings.Contains(inHandler, want) {
+			t.Errorf("handler labels missing %s", want)
+		}
+	}
+}
+
+func TestProfileLabels_Disabled(t *testing.T) {
+	if kv := profileLabels(context.Background(), "New", "x", nil); kv != nil {
+		t.Fatalf("expected no labels when disabled, got %v", kv)
+	}
+}
+
+func TestSetLabel_DoesNotMutateParent(t *testing.T) {
+	parent := []string{LabelOp, "New", LabelName, "a"}
+	child := setLabel(parent, LabelOp, "Then")
+	if parent[1] != "New" || child[1] != "Then" || child[3] != "a" {
+		t.Fatalf("parent %v, child %v", parent, child)
+	}
+}
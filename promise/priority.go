@@ -0,0 +1,256 @@
+package promise
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// -------------------------------------------------------
+// 优先级：延迟敏感的链路优先于批量任务调度
+// -------------------------------------------------------
+
+// Priority 任务优先级，零值为 PriorityNormal
+type Priority int8
+
+const (
+	PriorityLow    Priority = -1 // 后台批量任务
+	PriorityNormal Priority = 0
+	PriorityHigh   Priority = 1 // 交互式请求等延迟敏感的链路
+)
+
+func (p Priority) String() string {
+	switch {
+	case p < PriorityNormal:
+		return "low"
+	case p > PriorityNormal:
+		return "high"
+	default:
+		return "normal"
+	}
+}
+
+// level 多级队列下标：0 为最高优先级
+func (p Priority) level() int {
+	switch {
+	case p < PriorityNormal:
+		return 2
+	case p > PriorityNormal:
+		return 0
+	default:
+		return 1
+	}
+}
+
+// WithPriority 设置 Promise 的优先级，由 Then / Catch / Finally / Map / Timeout 派生的 Promise 继承，
+// All / Any / Race / AllSettled 取输入中的最高优先级。
+// 只有 GlobalDispatcher 实现 PriorityDispatcher (如 PriorityPool) 时才会影响调度顺序。
+func WithPriority(p Priority) Option {
+	return func(o *options) {
+		o.priority = p
+	}
+}
+
+// Priority 返回 Promise 的优先级
+func (p *Promise[T]) Priority() Priority {
+	return p.priority
+}
+
+// highestPriority 聚合操作的优先级：输入中的最高优先级
+func highestPriority[T any](promises []*Promise[T]) Priority {
+	prio := PriorityNormal
+	for i, p := range promises {
+		if i == 0 || p.priority > prio {
+			prio = p.priority
+		}
+	}
+	return prio
+}
+
+// PriorityDispatcher 区分优先级的调度器 (可选接口)
+// GlobalDispatcher 实现该接口时，executor 与 Promise 反应都通过 DispatchPriority 派发，并带上所属 Promise 的优先级。
+type PriorityDispatcher interface {
+	TaskDispatcher
+	DispatchPriority(p Priority, f func())
+}
+
+// defaultAging PriorityPool 默认的老化步长
+const defaultAging = 10 * time.Millisecond
+
+// priorityTask 排队中的任务
+type priorityTask struct {
+	f      func()
+	queued time.Time
+}
+
+// PriorityPool 固定数量 worker 的优先级协程池，高 / 普通 / 低三级队列
+// worker 空闲时比较各级队首任务的有效优先级 (基础优先级 + 已等待时长 / aging)，取最高者执行，
+// 因此低优先级任务每多等待一个 aging 就提升一级，不会在持续的高优先级负载下饿死。
+// 等待时长由 GlobalClock 计算，测试中可注入假时钟。
+// 实现 Dispatcher：Drain / Close 之后新的 executor 被拒绝 (New 以 ErrDispatcherClosed 拒绝)，已有链路的回调仍会执行。
+//
+//	pool := promise.NewPriorityPool(runtime.GOMAXPROCS(0), 0)
+//	promise.SetDispatcher(pool)
+//	defer pool.Close()
+//
+//	promise.New(handleRequest, promise.WithPriority(promise.PriorityHigh))
+//	promise.New(rebuildIndex, promise.WithPriority(promise.PriorityLow))
+type PriorityPool struct {
+	aging time.Duration
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	queues  [3][]priorityTask // 受 mu 保护，下标见 Priority.level
+	running int               // 正在执行的任务数
+	stopped bool              // worker 在队列清空后退出，之后提交的任务各自启动 Goroutine 执行
+	drain   drainState        // 关闭后没有正在执行的任务且队列为空时通知 Drain，受 mu 保护
+
+	wg sync.WaitGroup
+}
+
+// NewPriorityPool 创建并启动协程池
+// workers <= 0 时为 1；aging <= 0 时使用默认的 10ms
+func NewPriorityPool(workers int, aging time.Duration) *PriorityPool {
+	if workers <= 0 {
+		workers = 1
+	}
+	if aging <= 0 {
+		aging = defaultAging
+	}
+	pp := &PriorityPool{aging: aging}
+	pp.cond = sync.NewCond(&pp.mu)
+	pp.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go pp.worker()
+	}
+	return pp
+}
+
+// Dispatch 以 PriorityNormal 提交任务，实现 TaskDispatcher
+func (pp *PriorityPool) Dispatch(f func()) {
+	pp.DispatchPriority(PriorityNormal, f)
+}
+
+// DispatchPriority 按优先级提交任务，实现 PriorityDispatcher
+// 关闭期间仍会接受并排队 (Promise 反应不能被拒绝)，停止之后改为各自启动 Goroutine 执行。
+func (pp *PriorityPool) DispatchPriority(p Priority, f func()) {
+	pp.mu.Lock()
+	if pp.stopped {
+		pp.mu.Unlock()
+		go runTask(f)
+		return
+	}
+	pp.push(p, f)
+	pp.mu.Unlock()
+	pp.cond.Signal()
+}
+
+// DispatchTask 按 t.Priority 提交 executor，实现 Dispatcher；已关闭时返回 ErrDispatcherClosed，t.Ctx 已结束时返回 t.Ctx.Err()
+func (pp *PriorityPool) DispatchTask(t Task) error {
+	if err := t.Ctx.Err(); err != nil {
+		return err
+	}
+	pp.mu.Lock()
+	if pp.drain.closed.Load() {
+		pp.mu.Unlock()
+		return ErrDispatcherClosed
+	}
+	pp.push(t.Priority, t.Run)
+	pp.mu.Unlock()
+	pp.cond.Signal()
+	return nil
+}
+
+// Drain 实现 Drainer，排空后停止所有 worker，之后提交的回调各自启动 Goroutine 执行
+func (pp *PriorityPool) Drain(ctx context.Context) error {
+	pp.mu.Lock()
+	done := pp.drain.close()
+	pp.drain.notify(pp.idle())
+	pp.mu.Unlock()
+	if err := waitDrained(ctx, done); err != nil {
+		return err
+	}
+
+	pp.mu.Lock()
+	pp.stopped = true
+	pp.mu.Unlock()
+	pp.cond.Broadcast()
+	pp.wg.Wait()
+	return nil
+}
+
+// Close 等价于 Drain(context.Background())
+func (pp *PriorityPool) Close() {
+	_ = pp.Drain(context.Background())
+}
+
+func (pp *PriorityPool) worker() {
+	defer pp.wg.Done()
+	pp.mu.Lock()
+	defer pp.mu.Unlock()
+	for {
+		f := pp.next(GlobalClock.Now())
+		if f == nil {
+			if pp.stopped {
+				return
+			}
+			pp.drain.notify(pp.idle())
+			pp.cond.Wait()
+			continue
+		}
+
+		pp.running++
+		pp.mu.Unlock()
+		runTask(f)
+		pp.mu.Lock()
+		pp.running--
+	}
+}
+
+// push 任务入队 (调用方需持有 mu)
+func (pp *PriorityPool) push(p Priority, f func()) {
+	l := p.level()
+	pp.queues[l] = append(pp.queues[l], priorityTask{f: f, queued: GlobalClock.Now()})
+}
+
+// idle 没有正在执行的任务且队列为空 (调用方需持有 mu)
+func (pp *PriorityPool) idle() bool {
+	if pp.running > 0 {
+		return false
+	}
+	for l := range pp.queues {
+		if len(pp.queues[l]) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// next 取出有效优先级最高的队首任务，相同时基础优先级高者优先 (调用方需持有 mu)
+func (pp *PriorityPool) next(now time.Time) func() {
+	best, bestScore := -1, int64(0)
+	for l := range pp.queues {
+		if len(pp.queues[l]) == 0 {
+			continue
+		}
+		// 基础分：高 = 2，普通 = 1，低 = 0
+		score := int64(len(pp.queues)-1-l) + int64(now.Sub(pp.queues[l][0].queued)/pp.aging)
+		if best < 0 || score > bestScore {
+			best, bestScore = l, score
+		}
+	}
+	if best < 0 {
+		return nil
+	}
+	q := pp.queues[best]
+	f := q[0].f
+	q[0] = priorityTask{}
+	pp.queues[best] = q[1:]
+	return f
+}
+
+// 编译期检查
+var (
+	_ PriorityDispatcher = (*PriorityPool)(nil)
+	_ Dispatcher         = (*PriorityPool)(nil)
+)
@@ -0,0 +1,188 @@
+package promise
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPriority_PropagatesToContinuations(t *testing.T) {
+	high := New(func(resolve func(int), reject func(error)) { resolve(1) }, WithPriority(PriorityHigh))
+	low := NewWithContext(context.Background(), func(resolve func(int), reject func(error)) { resolve(2) }, WithPriority(PriorityLow))
+
+	then := high.Then(nil, nil)
+	finally := low.Catch(nil).Finally(func() {})
+	mapped := Map(high, func(v int) (string, error) { return "", nil })
+	timeout := low.Timeout(time.Second, "")
+	all := All(low, high)
+	race := Race(low, low)
+
+	cases := []struct {
+		name string
+		got  Priority
+		want Priority
+	}{
+		{"Then", then.Priority(), PriorityHigh},
+		{"Catch.Finally", finally.Priority(), PriorityLow},
+		{"Map", mapped.Priority(), PriorityHigh},
+		{"Timeout", timeout.Priority(), PriorityLow},
+		{"All", all.Priority(), PriorityHigh},
+		{"Race", race.Priority(), PriorityLow},
+		{"Resolve", Resolve(1).Priority(), PriorityNormal},
+	}
+	for _, c := range cases {
+		if c.got != c.want {
+			t.Errorf("%s: priority = %v, want %v", c.name, c.got, c.want)
+		}
+	}
+
+	// 等待所有链路结束，避免后台任务与后续用例替换调度器发生竞争
+	ctx := context.Background()
+	_, _ = then.Await(ctx)
+	_, _ = finally.Await(ctx)
+	_, _ = mapped.Await(ctx)
+	_, _ = timeout.Await(ctx)
+	_, _ = all.Await(ctx)
+	_, _ = race.Await(ctx)
+}
+
+// recordingPriorityDispatcher 记录每个任务的优先级后立即执行
+type recordingPriorityDispatcher struct {
+	prios chan Priority
+}
+
+func (d *recordingPriorityDispatcher) Dispatch(f func()) {
+	d.DispatchPriority(PriorityNormal, f)
+}
+
+func (d *recordingPriorityDispatcher) DispatchPriority(p Priority, f func()) {
+	d.prios <- p
+	go f()
+}
+
+func TestPriority_DispatchedWithPriority(t *testing.T) {
+	d := &recordingPriorityDispatcher{prios: make(chan Priority, 2)}
+	setGlobal[TaskDispatcher](t, SetDispatcher, GlobalDispatcher, d)
+
+	p := New(func(resolve func(int), reject func(error)) { resolve(1) }, WithPriority(PriorityHigh))
+	if _, err := p.Then(nil, nil).Await(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// executor 与 Then 的反应各派发一次 (反应可能已在决议方直接执行)
+	if got := <-d.prios; got != PriorityHigh {
+		t.Fatalf("executor dispatched with %v, want high", got)
+	}
+	select {
+	case got := <-d.prios:
+		if got != PriorityHigh {
+			t.Fatalf("reaction dispatched with %v, want high", got)
+		}
+	default:
+	}
+}
+
+func TestPriorityPool_Order(t *testing.T) {
+	pool := NewPriorityPool(1, time.Hour)
+	defer pool.Close()
+
+	// 阻塞唯一的 worker，排队后一次性放行
+	block := make(chan struct{})
+	pool.Dispatch(func() { <-block })
+
+	order := make(chan Priority, 3)
+	for _, p := range []Priority{PriorityLow, PriorityNormal, PriorityHigh} {
+		p := p
+		pool.DispatchPriority(p, func() { order <- p })
+	}
+	close(block)
+
+	for _, want := range []Priority{PriorityHigh, PriorityNormal, PriorityLow} {
+		if got := <-order; got != want {
+			t.Fatalf("ran %v, want %v", got, want)
+		}
+	}
+}
+
+// manualClock 只能手动推进的时钟，AfterFunc 仍使用真实时间
+type manualClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *manualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *manualClock) AfterFunc(d time.Duration, f func()) Timer {
+	return realClock{}.AfterFunc(d, f)
+}
+
+func (c *manualClock) advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+}
+
+func TestPriorityPool_Aging(t *testing.T) {
+	clock := &manualClock{now: time.Unix(0, 0)}
+	setGlobal[Clock](t, SetClock, GlobalClock, clock)
+
+	pool := NewPriorityPool(1, 0)
+	defer pool.Close()
+
+	block := make(chan struct{})
+	pool.Dispatch(func() { <-block })
+
+	order := make(chan Priority, 2)
+	pool.DispatchPriority(PriorityLow, func() { order <- PriorityLow })
+	// 低优先级任务已等待多个老化步长，有效优先级高于刚提交的高优先级任务
+	clock.advance(3 * defaultAging)
+	pool.DispatchPriority(PriorityHigh, func() { order <- PriorityHigh })
+	close(block)
+
+	if got := <-order; got != PriorityLow {
+		t.Fatalf("ran %v first, aged low priority task should run first", got)
+	}
+}
+
+func TestPriorityPool_NoAgingWithoutTime(t *testing.T) {
+	clock := &manualClock{now: time.Unix(0, 0)}
+	setGlobal[Clock](t, SetClock, GlobalClock, clock)
+
+	pool := NewPriorityPool(1, 0)
+	defer pool.Close()
+
+	block := make(chan struct{})
+	pool.Dispatch(func() { <-block })
+
+	// 时钟不走时即使真实时间流逝，低优先级任务也不会提升
+	order := make(chan Priority, 2)
+	pool.DispatchPriority(PriorityLow, func() { order <- PriorityLow })
+	time.Sleep(3 * defaultAging)
+	pool.DispatchPriority(PriorityHigh, func() { order <- PriorityHigh })
+	close(block)
+
+	if got := <-order; got != PriorityHigh {
+		t.Fatalf("ran %v first, want high priority without aging", got)
+	}
+}
+
+func TestPriorityPool_CloseDrainsQueuedTasks(t *testing.T) {
+	pool := NewPriorityPool(2, 0)
+
+	done := make(chan struct{}, 100)
+	for i := 0; i < 100; i++ {
+		pool.DispatchPriority(Priority(i%3-1), func() { done <- struct{}{} })
+	}
+	pool.Close()
+	if len(done) != 100 {
+		t.Fatalf("ran %d tasks before Close returned, want 100", len(done))
+	}
+
+	// Close 之后提交的任务仍会执行
+	pool.Dispatch(func() { done <- struct{}{} })
+	<-done
+}
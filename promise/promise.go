@@ -4,6 +4,7 @@ import (
 	"context"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
 // State 枚举
@@ -15,6 +16,9 @@ const (
 	Rejected
 )
 
+// released 内部状态：调试模式下 Release 后的 Pooled Promise 被置为该状态，之后的任何使用都会 panic (见 pooled.go)
+const released uint32 = 3
+
 func (s State) String() string {
 	switch s {
 	case Fulfilled:
@@ -32,6 +36,7 @@ func (s State) String() string {
 
 type handlerNode struct {
 	fn   func()
+	w    *waiter // 非 nil 时为 Await / Wait 挂载的等待者，fn 为 nil
 	next *handlerNode
 }
 
@@ -48,10 +53,34 @@ func getHandlerNode(fn func()) *handlerNode {
 	return node
 }
 
+// waiter 等待原语，挂在回调链表上，决议时先于回调被唤醒
+//   - 池化的等待者：Wait 及不会结束的 ctx 使用，ch 容量为 1，唤醒时写入，等待方读取后归还对象池
+//   - 共享的等待者：可取消的 ctx 使用 (见 sharedWaiter)，唤醒时关闭 ch，每个 Promise 至多一个
+type waiter struct {
+	ch     chan struct{}
+	shared bool
+}
+
+var waiterPool = sync.Pool{
+	New: func() interface{} {
+		return &waiter{ch: make(chan struct{}, 1)}
+	},
+}
+
+// wake 由决议方调用
+func (w *waiter) wake() {
+	if w.shared {
+		close(w.ch)
+		return
+	}
+	w.ch <- struct{}{}
+}
+
 func putHandlerChain(head *handlerNode) {
 	for head != nil {
 		next := head.next
 		head.fn = nil // 防止闭包引用泄漏
+		head.w = nil
 		head.next = nil
 		handlerNodePool.Put(head)
 		head = next
@@ -62,34 +91,60 @@ func putHandlerChain(head *handlerNode) {
 // Promise 核心结构体
 // -------------------------------------------------------
 
+// Promise 的注册与决议由 mu 串行化：
+//   - 决议方持锁写入结果、取走回调链表并发布最终状态，之后在锁外唤醒等待者并执行回调
+//   - handlers 为尾插链表，按注册顺序 (FIFO) 执行；注册时持锁检查状态，已决议时返回失败并由调用方直接派发
+//   - Await / Wait 以池化的 waiter 节点挂在同一个链表上，决议时先于回调被唤醒
+//   - 读取状态 (GetState、Await 的快速路径) 不持锁
 type Promise[T any] struct {
 	val          T
 	err          error
-	handlers     *handlerNode // 链表头
-	handlersTail *handlerNode // 链表尾 (尾插法)
-	signal       chan struct{}
 	mu           sync.Mutex
+	handlers     *handlerNode // 链表头 (尾插法)
+	handlersTail *handlerNode // 链表尾
 	state        uint32
+	depth        uint32                 // 连续内联执行的跳数，见 react
+	inline       bool                   // 链路是否以内联模式执行回调 (WithInline)
+	priority     Priority               // 派发任务的优先级 (WithPriority)，由派生的 Promise 继承
+	settled      atomic.Uint32          // 决议方是否已执行完回调、不再访问 Promise 的字段，见 waitSettled
+	shared       atomic.Pointer[waiter] // 可取消的 Await 共享的等待者，见 sharedWaiter
+	obs          *observation           // 可观测性元数据，仅在开启钩子/调试模式或命名时分配
 }
 
 // New 创建 Promise
-func New[T any](executor func(resolve func(T), reject func(error))) *Promise[T] {
-	p := &Promise[T]{}
+func New[T any](executor func(resolve func(T), reject func(error)), opts ...Option) *Promise[T] {
+	o := applyOptions(opts)
+	p := &Promise[T]{inline: o.inline, priority: o.priority}
+	p.observeNamed(nil, "New", nil, o.name)
+	return p.run("New", executor)
+}
 
-	GlobalDispatcher.Dispatch(func() {
-		defer handlePanic(p.Reject)
+// run 通过调度器异步执行 executor，供 New 及各派生操作共用
+func (p *Promise[T]) run(op string, executor func(resolve func(T), reject func(error))) *Promise[T] {
+	err := dispatch(context.Background(), p.obs, p.priority, func() {
+		defer handlePanic(p.obs, p.Reject)
+		if p.obs != nil {
+			defer p.obs.handlerRun(op, time.Now())
+		}
 		executor(p.Resolve, p.Reject)
 	})
-
+	if err != nil {
+		p.Reject(err)
+	}
 	return p
 }
 
 // NewWithContext 包含 Context 支持
-func NewWithContext[T any](ctx context.Context, executor func(resolve func(T), reject func(error))) *Promise[T] {
-	p := &Promise[T]{}
-
-	GlobalDispatcher.Dispatch(func() {
-		defer handlePanic(p.Reject)
+func NewWithContext[T any](ctx context.Context, executor func(resolve func(T), reject func(error)), opts ...Option) *Promise[T] {
+	o := applyOptions(opts)
+	p := &Promise[T]{inline: o.inline, priority: o.priority}
+	p.observeNamed(ctx, "NewWithContext", nil, o.name)
+
+	err := dispatch(ctx, p.obs, p.priority, func() {
+		defer handlePanic(p.obs, p.Reject)
+		if p.obs != nil {
+			defer p.obs.handlerRun("NewWithContext", time.Now())
+		}
 
 		if ctx.Err() != nil {
 			p.Reject(ctx.Err())
@@ -111,17 +166,25 @@ func NewWithContext[T any](ctx context.Context, executor func(resolve func(T), r
 
 		executor(safeResolve, safeReject)
 	})
+	if err != nil {
+		p.Reject(err)
+	}
 
 	return p
 }
 
 func (p *Promise[T]) GetState() State {
-	return State(atomic.LoadUint32(&p.state))
+	s := atomic.LoadUint32(&p.state)
+	if s == released {
+		panic(errUseAfterRelease)
+	}
+	return State(s)
 }
 
 // Resolve 触发 Promise 完成
 func (p *Promise[T]) Resolve(val T) {
 	if atomic.LoadUint32(&p.state) != uint32(Pending) {
+		p.checkReleased()
 		return
 	}
 	p.doResolve(val)
@@ -129,58 +192,192 @@ func (p *Promise[T]) Resolve(val T) {
 
 func (p *Promise[T]) doResolve(val T) {
 	p.mu.Lock()
-	if p.state != uint32(Pending) {
+	if atomic.LoadUint32(&p.state) != uint32(Pending) {
 		p.mu.Unlock()
 		return
 	}
-
 	p.val = val
+	h, d := p.takeHandlers()
 	atomic.StoreUint32(&p.state, uint32(Fulfilled))
-
-	h := p.handlers
-	p.handlers = nil
-	p.handlersTail = nil
-
-	if p.signal != nil {
-		close(p.signal)
-	}
 	p.mu.Unlock()
 
-	p.runHandlers(h)
+	p.complete(h, d, false)
 }
 
 // Reject 触发 Promise 拒绝
 func (p *Promise[T]) Reject(err error) {
 	if atomic.LoadUint32(&p.state) != uint32(Pending) {
+		p.checkReleased()
 		return
 	}
-	p.doReject(err)
+	p.doReject(err, false)
 }
 
-func (p *Promise[T]) doReject(err error) {
+// rejectPropagated 以上游 Promise 的拒绝原因拒绝 (错误沿链路传播，而非在此处产生)
+func (p *Promise[T]) rejectPropagated(err error) {
+	if atomic.LoadUint32(&p.state) != uint32(Pending) {
+		p.checkReleased()
+		return
+	}
+	p.doReject(err, true)
+}
+
+func (p *Promise[T]) doReject(err error, propagated bool) {
 	p.mu.Lock()
-	if p.state != uint32(Pending) {
+	if atomic.LoadUint32(&p.state) != uint32(Pending) {
 		p.mu.Unlock()
 		return
 	}
-
-	p.err = err
+	p.err = p.traceRejection(p.wrapRejection(err))
+	h, d := p.takeHandlers()
 	atomic.StoreUint32(&p.state, uint32(Rejected))
+	p.mu.Unlock()
 
+	p.complete(h, d, propagated)
+}
+
+// takeHandlers 在发布最终状态之前 (持有 mu) 取走回调链表，并读取执行回调的调度器 (没有回调时不读取)：
+// Await 看到最终状态即可返回，调用方随后可能立即替换 GlobalDispatcher
+func (p *Promise[T]) takeHandlers() (*handlerNode, TaskDispatcher) {
 	h := p.handlers
-	p.handlers = nil
-	p.handlersTail = nil
+	if h == nil {
+		return nil, nil
+	}
+	p.handlers, p.handlersTail = nil, nil
+	return h, GlobalDispatcher
+}
+
+// complete 发布最终状态之后在锁外调用：上报决议事件、唤醒等待者并通过 d 执行回调链表 h
+func (p *Promise[T]) complete(h *handlerNode, d TaskDispatcher, propagated bool) {
+	// 先上报决议事件再唤醒等待者，保证 Await 返回时钩子已观察到决议
+	p.observeSettle(propagated)
+	h = wakeWaiters(h)
+	h = queueReactions(d, h)
+	runHandlers(d, h)
+	p.markSettled()
+}
+
+// settled 的取值
+const (
+	settleRunning uint32 = iota // 决议方尚未执行完 complete
+	settleDone
+	settleWaiting // 有 Release 在等待 complete 结束
+)
 
-	if p.signal != nil {
-		close(p.signal)
+// settleMu / settleCond 供 waitSettled 挂起，所有 Promise 共用 (等待极少发生)
+var (
+	settleMu   sync.Mutex
+	settleCond = sync.NewCond(&settleMu)
+)
+
+// markSettled complete 的最后一步，之后决议方不再访问 p；有 Release 在等待时唤醒它
+func (p *Promise[T]) markSettled() {
+	if p.settled.Swap(settleDone) == settleWaiting {
+		settleMu.Lock()
+		settleCond.Broadcast()
+		settleMu.Unlock()
+	}
+}
+
+// waitSettled 等待决议方执行完 complete (上报决议事件、唤醒等待者并执行已注册的回调)
+// Await 在决议方发布状态后即可返回，此时决议方可能仍在执行回调。
+func (p *Promise[T]) waitSettled() {
+	if p.settled.Load() == settleDone {
+		return
+	}
+	settleMu.Lock()
+	for p.settled.Load() != settleDone {
+		// 标记为等待中后挂起；持有 settleMu 期间 markSettled 无法完成唤醒，因此不会丢失信号
+		if p.settled.CompareAndSwap(settleRunning, settleWaiting) || p.settled.Load() == settleWaiting {
+			settleCond.Wait()
+		}
 	}
+	settleMu.Unlock()
+}
+
+// pushHandler 注册回调，Promise 已决议时返回 false
+func (p *Promise[T]) pushHandler(fn func()) bool {
+	node := getHandlerNode(fn)
+	if !p.pushNode(node) {
+		node.fn = nil
+		handlerNodePool.Put(node)
+		return false
+	}
+	if p.obs != nil {
+		p.obs.handlers.Add(1)
+	}
+	return true
+}
+
+// pushNode 把节点追加到回调链表尾，Promise 已决议时返回 false
+func (p *Promise[T]) pushNode(node *handlerNode) bool {
+	p.mu.Lock()
+	if atomic.LoadUint32(&p.state) != uint32(Pending) {
+		p.mu.Unlock()
+		return false
+	}
+	if p.handlersTail == nil {
+		p.handlers = node
+	} else {
+		p.handlersTail.next = node
+	}
+	p.handlersTail = node
 	p.mu.Unlock()
+	return true
+}
 
-	p.runHandlers(h)
+// wakeWaiters 唤醒链表中的所有等待者并回收其节点，按原顺序返回剩余的回调
+func wakeWaiters(head *handlerNode) *handlerNode {
+	var first, last *handlerNode
+	for node := head; node != nil; {
+		next := node.next
+		if node.w == nil {
+			node.next = nil
+			if last == nil {
+				first = node
+			} else {
+				last.next = node
+			}
+			last = node
+		} else {
+			node.w.wake()
+			node.w = nil
+			node.next = nil
+			handlerNodePool.Put(node)
+		}
+		node = next
+	}
+	return first
 }
 
-// runHandlers 遍历链表执行并回收
-func (p *Promise[T]) runHandlers(head *handlerNode) {
+// queueReactions 调度器 d 区分微任务时 (如 EventLoop)，把已注册的回调逐个作为微任务排队并返回 nil，
+// 否则原样返回，由 runHandlers 在决议方的调用栈上直接执行
+func queueReactions(d TaskDispatcher, head *handlerNode) *handlerNode {
+	if head == nil {
+		return nil
+	}
+	md, ok := d.(MicrotaskDispatcher)
+	if !ok {
+		return head
+	}
+	for node := head; node != nil; node = node.next {
+		md.DispatchMicrotask(node.fn)
+	}
+	putHandlerChain(head)
+	return nil
+}
+
+// runHandlers 执行已注册的回调：数量达到扇出阈值且 d 支持批量派发时切分给多个 worker (见 fanOut)，
+// 否则在当前 Goroutine 上按注册顺序执行
+func runHandlers(d TaskDispatcher, head *handlerNode) {
+	if head == nil || fanOut(d, head) {
+		return
+	}
+	runChain(head)
+}
+
+// runChain 遍历链表执行并回收
+func runChain(head *handlerNode) {
 	current := head
 	for current != nil {
 		func(fn func()) {
@@ -201,18 +398,25 @@ func (p *Promise[T]) runHandlers(head *handlerNode) {
 // Then 链式调用
 // 修复核心：手动创建 child promise，并在当前 Goroutine 同步注册回调，保证顺序。
 func (p *Promise[T]) Then(onFulfilled func(T) T, onRejected func(error) error) *Promise[T] {
+	return p.then("Then", onFulfilled, onRejected, p.inline)
+}
+
+func (p *Promise[T]) then(op string, onFulfilled func(T) T, onRejected func(error) error, inline bool) *Promise[T] {
 	// 1. 手动创建 Child Promise (不通过 New 启动 Goroutine)
-	child := &Promise[T]{}
+	child := &Promise[T]{inline: p.inline, priority: p.priority}
+	child.observe(nil, op, p.obs)
 
 	// 2. 定义处理逻辑 (闭包捕获 child)
 	handle := func() {
-		defer handlePanic(child.Reject)
+		defer handlePanic(child.obs, child.Reject)
+		start := child.handlerStart()
 
 		// Fix QF1003: Use switch for state check
 		switch p.GetState() {
 		case Fulfilled:
 			if onFulfilled != nil {
 				res := onFulfilled(p.val)
+				child.handlerDone(op, start)
 				child.Resolve(res)
 			} else {
 				child.Resolve(p.val)
@@ -220,36 +424,18 @@ func (p *Promise[T]) Then(onFulfilled func(T) T, onRejected func(error) error) *
 		case Rejected:
 			if onRejected != nil {
 				err := onRejected(p.err)
+				child.handlerDone(op, start)
 				// 注意：在当前实现中，Catch 返回的是 error，所以继续 Reject
 				child.Reject(err)
 			} else {
-				child.Reject(p.err)
+				child.rejectPropagated(p.err)
 			}
 		}
 	}
 
 	// 3. 同步注册 (Synchronous Registration)
 	// 只有这样才能保证 TestPromise_ExecutionOrder_FIFO 中的调用顺序
-	if p.GetState() != Pending {
-		GlobalDispatcher.Dispatch(handle)
-	} else {
-		p.mu.Lock()
-		if p.state != uint32(Pending) {
-			p.mu.Unlock()
-			GlobalDispatcher.Dispatch(handle)
-		} else {
-			// 尾插法
-			node := getHandlerNode(handle)
-			if p.handlers == nil {
-				p.handlers = node
-				p.handlersTail = node
-			} else {
-				p.handlersTail.next = node
-				p.handlersTail = node
-			}
-			p.mu.Unlock()
-		}
-	}
+	react(p, child, inline, handle)
 
 	return child
 }
@@ -261,76 +447,133 @@ func (p *Promise[T]) Catch(onRejected func(error) error) *Promise[T] {
 // Finally 链式调用
 func (p *Promise[T]) Finally(onFinally func()) *Promise[T] {
 	// 1. 手动创建 Child Promise
-	child := &Promise[T]{}
+	child := &Promise[T]{inline: p.inline, priority: p.priority}
+	child.observe(nil, "Finally", p.obs)
 
 	// 2. 定义处理逻辑
 	handle := func() {
-		defer handlePanic(child.Reject)
+		defer handlePanic(child.obs, child.Reject)
+		start := child.handlerStart()
 		onFinally()
+		child.handlerDone("Finally", start)
 		// Finally 不改变结果，除非 Panic
 		if p.GetState() == Fulfilled {
 			child.Resolve(p.val)
 		} else {
-			child.Reject(p.err)
+			child.rejectPropagated(p.err)
 		}
 	}
 
 	// 3. 同步注册
-	if p.GetState() != Pending {
-		GlobalDispatcher.Dispatch(handle)
-	} else {
-		p.mu.Lock()
-		if p.state != uint32(Pending) {
-			p.mu.Unlock()
-			GlobalDispatcher.Dispatch(handle)
-		} else {
-			// 尾插法
-			node := getHandlerNode(handle)
-			if p.handlers == nil {
-				p.handlers = node
-				p.handlersTail = node
-			} else {
-				p.handlersTail.next = node
-				p.handlersTail = node
-			}
-			p.mu.Unlock()
-		}
-	}
+	react(p, child, p.inline, handle)
 
 	return child
 }
 
-// Await 阻塞等待结果
-func (p *Promise[T]) Await(ctx context.Context) (T, error) {
-	if s := p.GetState(); s == Fulfilled {
-		return p.val, nil
-	} else if s == Rejected {
-		return *new(T), p.err
+// react 挂载 child 的反应 handle
+// 普通模式：Pending 时注册到回调链表 (决议时执行)，已决议时派发
+// 内联模式：已决议时直接在当前 Goroutine 执行，Pending 时在决议方的 Goroutine 上执行；
+// 连续内联超过 maxInlineDepth 跳时改为派发，避免长链路在同一个调用栈上无限递归
+func react[T any, R any](p *Promise[T], child *Promise[R], inline bool, handle func()) {
+	if !inline {
+		if p.GetState() != Pending || !p.pushHandler(labeled(child.obs, handle)) {
+			dispatchReaction(child.obs, child.priority, handle)
+		}
+		return
 	}
 
-	p.mu.Lock()
-	if p.state == uint32(Fulfilled) {
-		p.mu.Unlock()
-		return p.val, nil
+	run := func() {
+		if p.depth >= maxInlineDepth {
+			dispatchReaction(child.obs, child.priority, handle)
+			return
+		}
+		child.depth = p.depth + 1
+		handle()
 	}
-	if p.state == uint32(Rejected) {
-		p.mu.Unlock()
-		return *new(T), p.err
+	if p.GetState() != Pending || !p.pushHandler(labeled(child.obs, run)) {
+		run()
 	}
+}
 
-	if p.signal == nil {
-		p.signal = make(chan struct{})
+// Await 阻塞等待结果
+// ctx 不会结束时 (如 context.Background()) 与 Wait 相同，使用池化的等待原语，不分配内存；
+// 否则同一个 Promise 上的所有 Await 共享一个等待节点，ctx 先结束的 Await 直接返回，不会在 Promise 上残留节点
+func (p *Promise[T]) Await(ctx context.Context) (T, error) {
+	if ctx.Done() == nil {
+		return p.Wait()
+	}
+	if p.GetState() != Pending {
+		return p.result()
 	}
-	sig := p.signal
-	p.mu.Unlock()
 
+	ch, ok := p.sharedWaiter()
+	if !ok {
+		return p.result()
+	}
 	select {
+	case <-ch:
 	case <-ctx.Done():
 		return *new(T), ctx.Err()
-	case <-sig:
-		if p.GetState() == Fulfilled {
-			return p.val, nil
+	}
+	return p.result()
+}
+
+// Wait 无 Context 的阻塞等待，语义等同 Await(context.Background())，少一次 select
+func (p *Promise[T]) Wait() (T, error) {
+	if p.GetState() == Pending {
+		if w, ok := p.park(); ok {
+			<-w.ch
+			waiterPool.Put(w)
 		}
-		return *new(T), p.err
 	}
+	return p.result()
+}
+
+// park 在 Pending Promise 上挂载池化的等待者，Promise 已决议时返回 false
+func (p *Promise[T]) park() (*waiter, bool) {
+	w := waiterPool.Get().(*waiter)
+	if !p.pushWaiter(w) {
+		waiterPool.Put(w)
+		return nil, false
+	}
+	return w, true
+}
+
+// sharedWaiter 返回 p 决议时关闭的通道，Promise 已决议时返回 false
+// 通道在第一次可取消的 Await 时创建并挂载到回调链表上，之后的 Await 复用它：
+// 等待方放弃时无需从链表中摘除节点，大量超时的 Await 也只占用一个节点。
+func (p *Promise[T]) sharedWaiter() (chan struct{}, bool) {
+	if w := p.shared.Load(); w != nil {
+		return w.ch, true
+	}
+	w := &waiter{ch: make(chan struct{}), shared: true}
+	if !p.shared.CompareAndSwap(nil, w) {
+		return p.shared.Load().ch, true
+	}
+	if !p.pushWaiter(w) {
+		// 已决议：其他 Await 可能已取到该通道，由这里关闭
+		close(w.ch)
+		return nil, false
+	}
+	return w.ch, true
+}
+
+// pushWaiter 把等待者挂到回调链表上，Promise 已决议时返回 false
+func (p *Promise[T]) pushWaiter(w *waiter) bool {
+	node := getHandlerNode(nil)
+	node.w = w
+	if !p.pushNode(node) {
+		node.w = nil
+		handlerNodePool.Put(node)
+		return false
+	}
+	return true
+}
+
+// result 读取已决议 Promise 的结果
+func (p *Promise[T]) result() (T, error) {
+	if p.GetState() == Fulfilled {
+		return p.val, nil
+	}
+	return *new(T), p.err
 }
@@ -10,6 +10,14 @@ import (
 	"time"
 )
 
+// setGlobal 通过 set 将一项全局配置改为 v，测试 (或基准测试) 结束时恢复为调用前的值 prev
+// 接口类型的配置需要显式实例化，例如 setGlobal[TaskDispatcher](t, SetDispatcher, GlobalDispatcher, d)
+func setGlobal[V any](tb testing.TB, set func(V), prev, v V) {
+	tb.Helper()
+	set(v)
+	tb.Cleanup(func() { set(prev) })
+}
+
 // 辅助断言函数
 func assertEqual(t *testing.T, expected, actual interface{}, msg string) {
 	t.Helper()
@@ -39,7 +47,9 @@ func TestPromise_BasicReject(t *testing.T) {
 	})
 
 	_, err := p.Await(context.Background())
-	assertEqual(t, expectedErr, err, "Basic Reject")
+	if !errors.Is(err, expectedErr) {
+		t.Errorf("Basic Reject: expected %v, got %v", expectedErr, err)
+	}
 }
 
 // 核心测试：验证链表翻转后的执行顺序是否为 FIFO
@@ -143,6 +153,11 @@ func TestPromise_PanicRecovery(t *testing.T) {
 		panic("boom")
 	})
 	_, err := p.Await(context.Background())
+	// 调试模式下拒绝原因被包装为 *RejectionError
+	var re *RejectionError
+	if errors.As(err, &re) {
+		err = re.Err
+	}
 	if err == nil || err.Error() != "panic: boom" {
 		t.Errorf("Expected panic error, got: %v", err)
 	}
@@ -161,3 +176,21 @@ func ExampleNew_basic() {
 	// Output:
 	// Hello Promise
 }
+
+func TestAny_AggregateError(t *testing.T) {
+	e1, e2 := errors.New("e1"), errors.New("e2")
+	_, err := Any(Reject[int](e1), Reject[int](e2)).Await(context.Background())
+
+	var agg *AggregateError
+	if !errors.As(err, &agg) {
+		t.Fatalf("expected *AggregateError, got %v", err)
+	}
+	if len(agg.Errors) != 2 || !errors.Is(agg.Errors[0], e1) || !errors.Is(agg.Errors[1], e2) {
+		t.Errorf("errors should follow input order: %v", agg.Errors)
+	}
+
+	_, err = Any[int]().Await(context.Background())
+	if !errors.As(err, &agg) || len(agg.Errors) != 0 {
+		t.Errorf("empty Any: expected empty *AggregateError, got %v", err)
+	}
+}
@@ -0,0 +1,165 @@
+package promisegraph
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Node 依赖关系图中的一个 Promise
+type Node struct {
+	ID    uint64 `json:"id"`
+	Op    string `json:"op"`
+	Name  string `json:"name,omitempty"`
+	State string `json:"state"` // "pending" / "fulfilled" / "rejected"
+	Error string `json:"error,omitempty"`
+	// Propagated 拒绝原因来自上游 Promise
+	Propagated bool `json:"propagated,omitempty"`
+
+	Created time.Time  `json:"created"`
+	Settled *time.Time `json:"settled,omitempty"`
+	// Duration 从创建到决议的耗时 (纳秒)，未决议时为 0
+	Duration time.Duration `json:"duration"`
+	// HandlerTime executor 与回调执行的总耗时 (纳秒)
+	HandlerTime time.Duration `json:"handler_time"`
+	Dispatches  int           `json:"dispatches"`
+}
+
+// Edge 从上游 Promise 指向下游 Promise 的依赖边
+type Edge struct {
+	From uint64 `json:"from"`
+	To   uint64 `json:"to"`
+	Kind string `json:"kind"` // 见 EdgeKind
+}
+
+// Graph 依赖关系图
+type Graph struct {
+	Nodes []Node `json:"nodes"`
+	Edges []Edge `json:"edges"`
+}
+
+// WriteJSON 以 JSON 格式输出，可通过 ReadJSON 读回
+func (g *Graph) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(g)
+}
+
+// ReadJSON 读取 WriteJSON 输出的依赖关系图
+func ReadJSON(r io.Reader) (*Graph, error) {
+	var g Graph
+	if err := json.NewDecoder(r).Decode(&g); err != nil {
+		return nil, fmt.Errorf("promisegraph: decode graph: %w", err)
+	}
+	return &g, nil
+}
+
+// stateColors 各状态节点的填充色
+var stateColors = map[string]string{
+	"fulfilled": "#c8e6c9",
+	"rejected":  "#ffcdd2",
+	"pending":   "#eeeeee",
+}
+
+// WriteDOT 以 Graphviz DOT 格式输出，例如：
+//
+//	digraph promises {
+//	  n1 [label="#1 New\n\"load-user\"\nfulfilled 12ms" fillcolor="#c8e6c9"];
+//	  n1 -> n2 [label="then"];
+//	}
+func (g *Graph) WriteDOT(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	bw.WriteString("digraph promises {\n")
+	bw.WriteString("  rankdir=LR;\n")
+	bw.WriteString("  node [shape=box style=\"rounded,filled\" fontname=\"Helvetica\"];\n")
+	bw.WriteString("  edge [fontname=\"Helvetica\" fontsize=10];\n")
+
+	for _, n := range g.Nodes {
+		lines := []string{fmt.Sprintf("#%d %s", n.ID, n.Op)}
+		if n.Name != "" {
+			lines = append(lines, fmt.Sprintf("%q", n.Name))
+		}
+		status := n.State
+		if n.Settled != nil {
+			status += " " + n.Duration.Round(time.Microsecond).String()
+		}
+		lines = append(lines, status)
+		if n.Error != "" && !n.Propagated {
+			lines = append(lines, n.Error)
+		}
+
+		for i, l := range lines {
+			lines[i] = dotEscape(l)
+		}
+		color, ok := stateColors[n.State]
+		if !ok {
+			color = stateColors["pending"]
+		}
+		fmt.Fprintf(bw, "  n%d [label=\"%s\" fillcolor=\"%s\"];\n", n.ID, strings.Join(lines, `\n`), color)
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(bw, "  n%d -> n%d [label=\"%s\"];\n", e.From, e.To, dotEscape(e.Kind))
+	}
+
+	bw.WriteString("}\n")
+	return bw.Flush()
+}
+
+// dotEscape 转义 DOT 双引号字符串中的特殊字符
+func dotEscape(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return r.Replace(s)
+}
+
+// WriteTimeline 以文本时间线输出：按创建时间排序，每行一个 Promise，
+// 依次为相对首个 Promise 的创建偏移、耗时、ID、操作 (名称)、上游 ID 和最终状态，例如：
+//
+//	+0s      12ms  #1  New "load-user"          fulfilled
+//	+150µs   3ms   #2  Then          <- #1    rejected: db down
+func (g *Graph) WriteTimeline(w io.Writer) error {
+	nodes := append([]Node(nil), g.Nodes...)
+	sort.SliceStable(nodes, func(i, j int) bool {
+		if !nodes[i].Created.Equal(nodes[j].Created) {
+			return nodes[i].Created.Before(nodes[j].Created)
+		}
+		return nodes[i].ID < nodes[j].ID
+	})
+
+	parents := make(map[uint64][]string)
+	for _, e := range g.Edges {
+		parents[e.To] = append(parents[e.To], fmt.Sprintf("#%d", e.From))
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	var origin time.Time
+	if len(nodes) > 0 {
+		origin = nodes[0].Created
+	}
+	for _, n := range nodes {
+		op := n.Op
+		if n.Name != "" {
+			op = fmt.Sprintf("%s %q", n.Op, n.Name)
+		}
+		from := ""
+		if ps := parents[n.ID]; len(ps) > 0 {
+			from = "<- " + strings.Join(ps, ",")
+		}
+		duration := "-"
+		if n.Settled != nil {
+			duration = n.Duration.Round(time.Microsecond).String()
+		}
+		state := n.State
+		if n.Error != "" {
+			state += ": " + n.Error
+		}
+		fmt.Fprintf(tw, "+%v\t%s\t#%d\t%s\t%s\t%s\n",
+			n.Created.Sub(origin).Round(time.Microsecond), duration, n.ID, op, from, state)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,128 @@
+package promisegraph
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/xigexb/go-promise/promise"
+)
+
+func record(t *testing.T) *Recorder {
+	rec := NewRecorder()
+	promise.SetTracer(rec)
+	t.Cleanup(func() { promise.SetTracer(nil) })
+	return rec
+}
+
+func TestRecorder_FanOutFanIn(t *testing.T) {
+	rec := record(t)
+
+	src := promise.New(func(resolve func(int), reject func(error)) {
+		resolve(1)
+	}, promise.WithName("load-user"))
+	a := src.Then(func(v int) int { return v + 1 }, nil)
+	b := promise.Map(src, func(v int) (string, error) { return "", errors.New("boom") })
+	all := promise.All(a, src)
+	if _, err := all.Await(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	_, _ = b.Await(context.Background())
+
+	g := rec.Graph()
+	if len(g.Nodes) != 4 {
+		t.Fatalf("expected 4 nodes, got %+v", g.Nodes)
+	}
+
+	kinds := make(map[string]int)
+	for _, e := range g.Edges {
+		kinds[e.Kind]++
+	}
+	if kinds["then"] != 1 || kinds["map"] != 1 || kinds["aggregate"] != 2 {
+		t.Errorf("unexpected edges %+v", g.Edges)
+	}
+
+	byID := make(map[uint64]Node)
+	for _, n := range g.Nodes {
+		byID[n.ID] = n
+	}
+	if n := byID[src.ID()]; n.Name != "load-user" || n.State != "fulfilled" || n.Settled == nil || n.Dispatches != 1 {
+		t.Errorf("unexpected source node %+v", n)
+	}
+	if n := byID[b.ID()]; n.State != "rejected" || !strings.HasPrefix(n.Error, "boom") {
+		t.Errorf("unexpected map node %+v", n)
+	}
+}
+
+func TestGraph_JSONRoundTrip(t *testing.T) {
+	rec := record(t)
+	p := promise.Resolve(1).Then(func(v int) int { return v }, nil)
+	if _, err := p.Await(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := rec.Graph().WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	g, err := ReadJSON(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Nodes) != 2 || len(g.Edges) != 1 || g.Edges[0].Kind != "then" {
+		t.Fatalf("unexpected graph %+v", g)
+	}
+	if _, err := ReadJSON(strings.NewReader("{")); err == nil {
+		t.Error("expected decode error")
+	}
+}
+
+func TestGraph_WriteDOT(t *testing.T) {
+	g := &Graph{
+		Nodes: []Node{
+			{ID: 1, Op: "New", Name: `say "hi"`, State: "rejected", Error: "db down"},
+			{ID: 2, Op: "Then", State: "pending"},
+		},
+		Edges: []Edge{{From: 1, To: 2, Kind: "then"}},
+	}
+	var buf bytes.Buffer
+	if err := g.WriteDOT(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"digraph promises {",
+		`n1 [label="#1 New\n\"say \\\"hi\\\"\"\nrejected\ndb down" fillcolor="#ffcdd2"];`,
+		`n2 [label="#2 Then\npending" fillcolor="#eeeeee"];`,
+		`n1 -> n2 [label="then"];`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("DOT output missing %s\n%s", want, out)
+		}
+	}
+}
+
+func TestGraph_WriteTimeline(t *testing.T) {
+	rec := record(t)
+	p := promise.Resolve(1).Then(func(v int) int { return v }, nil)
+	if _, err := p.Await(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := rec.Graph().WriteTimeline(&buf); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %q", buf.String())
+	}
+	if !strings.HasPrefix(lines[0], "+0s") || !strings.Contains(lines[0], "Resolve") {
+		t.Errorf("unexpected first line %q", lines[0])
+	}
+	if !strings.Contains(lines[1], "Then") || !strings.Contains(lines[1], "<- #") || !strings.Contains(lines[1], "fulfilled") {
+		t.Errorf("unexpected second line %q", lines[1])
+	}
+}
@@ -0,0 +1,131 @@
+// Package promisegraph 记录 Promise 之间的依赖关系图，并导出为 Graphviz DOT、JSON 或文本时间线，
+// 用于理解由 All / Race / Then / Map 组合出的复杂扇出/扇入流程。
+//
+// Recorder 实现 promise.Tracer，只记录设置之后创建的 Promise：
+//
+//	rec := promisegraph.NewRecorder()
+//	promise.SetTracer(rec)
+//	// ... 运行业务流程 ...
+//	promise.SetTracer(nil)
+//	rec.Graph().WriteDOT(os.Stdout) // dot -Tsvg -o flow.svg
+//
+// 保存的 JSON 可以用 cmd/promisegraph 离线渲染为 DOT 或文本时间线。
+// 全局只能设置一个 Tracer，记录期间 promiseotel 等其他 Tracer 不会收到事件。
+package promisegraph
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/xigexb/go-promise/promise"
+)
+
+// Recorder 实现 promise.Tracer，在内存中累积依赖关系图
+// 所有节点都会被保留直到 Reset，适合在排障或测试时短时间开启
+type Recorder struct {
+	mu    sync.Mutex
+	nodes map[uint64]*Node
+	edges []Edge
+}
+
+// NewRecorder 创建空的记录器
+func NewRecorder() *Recorder {
+	return &Recorder{nodes: make(map[uint64]*Node)}
+}
+
+// OnCreate 实现 promise.Tracer
+func (r *Recorder) OnCreate(ev promise.CreateEvent) {
+	kind := EdgeKind(ev.Op)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nodes[ev.ID] = &Node{
+		ID:      ev.ID,
+		Op:      ev.Op,
+		Name:    ev.Name,
+		State:   promise.Pending.String(),
+		Created: ev.Time,
+	}
+	for _, parent := range ev.Parents {
+		r.edges = append(r.edges, Edge{From: parent, To: ev.ID, Kind: kind})
+	}
+}
+
+// OnDispatch 实现 promise.Tracer
+func (r *Recorder) OnDispatch(ev promise.DispatchEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if n, ok := r.nodes[ev.ID]; ok {
+		n.Dispatches++
+	}
+}
+
+// OnSettle 实现 promise.Tracer
+func (r *Recorder) OnSettle(ev promise.SettleEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n, ok := r.nodes[ev.ID]
+	if !ok {
+		return
+	}
+	n.State = ev.State.String()
+	settled := ev.Time
+	n.Settled = &settled
+	n.Duration = ev.Time.Sub(n.Created)
+	if ev.Err != nil {
+		n.Error = ev.Err.Error()
+	}
+	n.Propagated = ev.Propagated
+}
+
+// OnHandlerRun 实现 promise.Tracer
+func (r *Recorder) OnHandlerRun(ev promise.HandlerEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if n, ok := r.nodes[ev.ID]; ok {
+		n.HandlerTime += ev.Duration
+	}
+}
+
+// Reset 清空已记录的节点和边
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nodes = make(map[uint64]*Node)
+	r.edges = nil
+}
+
+// Graph 返回当前依赖关系图的快照，节点按 ID 排序
+// 上游 Promise 创建于记录开始之前时，对应的边会被省略
+func (r *Recorder) Graph() *Graph {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	g := &Graph{Nodes: make([]Node, 0, len(r.nodes))}
+	for _, n := range r.nodes {
+		g.Nodes = append(g.Nodes, *n)
+	}
+	sort.Slice(g.Nodes, func(i, j int) bool { return g.Nodes[i].ID < g.Nodes[j].ID })
+
+	for _, e := range r.edges {
+		if _, ok := r.nodes[e.From]; ok {
+			g.Edges = append(g.Edges, e)
+		}
+	}
+	return g
+}
+
+// EdgeKind 由子 Promise 的创建操作推导边的类型：
+// "then"、"map"、"finally"、"timeout"，聚合操作 (All / Any / Race / AllSettled) 为 "aggregate"，其他操作为小写的操作名
+func EdgeKind(op string) string {
+	switch op {
+	case "All", "Any", "Race", "AllSettled":
+		return "aggregate"
+	default:
+		return strings.ToLower(op)
+	}
+}
+
+// 编译期检查
+var _ promise.Tracer = (*Recorder)(nil)
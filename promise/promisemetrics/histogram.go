@@ -0,0 +1,60 @@
+package promisemetrics
+
+import (
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// Histogram 固定桶的无锁直方图，单位为秒
+type Histogram struct {
+	bounds []float64
+	counts []atomic.Int64 // len(bounds)+1，最后一个为 +Inf 桶
+	sumNs  atomic.Int64
+}
+
+func newHistogram(bounds []float64) *Histogram {
+	b := append([]float64(nil), bounds...)
+	sort.Float64s(b)
+	return &Histogram{
+		bounds: b,
+		counts: make([]atomic.Int64, len(b)+1),
+	}
+}
+
+// Observe 记录一次耗时
+func (h *Histogram) Observe(d time.Duration) {
+	sec := d.Seconds()
+	i := sort.SearchFloat64s(h.bounds, sec) // 第一个 >= sec 的桶
+	h.counts[i].Add(1)
+	h.sumNs.Add(int64(d))
+}
+
+// Bucket 直方图中的一个累计桶
+type Bucket struct {
+	UpperBound float64 `json:"le"`
+	Count      int64   `json:"count"` // 累计值 (<= UpperBound 的样本数)
+}
+
+// HistogramSnapshot 直方图快照
+type HistogramSnapshot struct {
+	Buckets []Bucket `json:"buckets"`
+	Count   int64    `json:"count"`
+	Sum     float64  `json:"sum"` // 秒
+}
+
+// Snapshot 读取累计桶 (不含 +Inf，+Inf 即 Count)
+// Count 由各桶累加得出，与并发 Observe 交错时也不会小于最后一个累计桶；Sum 单独读取，可能与桶相差正在记录的样本。
+func (h *Histogram) Snapshot() HistogramSnapshot {
+	s := HistogramSnapshot{
+		Buckets: make([]Bucket, len(h.bounds)),
+		Sum:     time.Duration(h.sumNs.Load()).Seconds(),
+	}
+	var cum int64
+	for i, b := range h.bounds {
+		cum += h.counts[i].Load()
+		s.Buckets[i] = Bucket{UpperBound: b, Count: cum}
+	}
+	s.Count = cum + h.counts[len(h.bounds)].Load()
+	return s
+}
@@ -0,0 +1,120 @@
+// Package promisemetrics 提供 promise.Metrics 的默认实现：
+// 计数器、直方图与仪表盘全部基于原子操作，可发布到 expvar，也可输出 Prometheus 文本格式 (无需 Prometheus 客户端库)。
+//
+//	m := promisemetrics.New()
+//	m.Publish("promise")                  // 出现在 /debug/vars
+//	http.Handle("/metrics", m.Handler())  // Prometheus 抓取
+//	promise.SetMetrics(m)
+package promisemetrics
+
+import (
+	"expvar"
+	"sync/atomic"
+	"time"
+
+	"github.com/xigexb/go-promise/promise"
+)
+
+// DefaultBuckets 直方图默认桶上界 (秒)
+var DefaultBuckets = []float64{0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5, 10}
+
+// Metrics 实现 promise.Metrics
+type Metrics struct {
+	created   atomic.Int64
+	fulfilled atomic.Int64
+	rejected  atomic.Int64
+	panicked  atomic.Int64
+
+	queued  atomic.Int64
+	started atomic.Int64
+
+	settle  *Histogram
+	handler *Histogram
+}
+
+// New 使用 DefaultBuckets 创建指标集
+func New() *Metrics {
+	return NewWithBuckets(DefaultBuckets)
+}
+
+// NewWithBuckets 使用自定义直方图桶 (秒，升序) 创建指标集
+func NewWithBuckets(buckets []float64) *Metrics {
+	return &Metrics{
+		settle:  newHistogram(buckets),
+		handler: newHistogram(buckets),
+	}
+}
+
+// PromiseCreated 实现 promise.Metrics
+func (m *Metrics) PromiseCreated(string) {
+	m.created.Add(1)
+}
+
+// PromiseSettled 实现 promise.Metrics
+func (m *Metrics) PromiseSettled(_ string, state promise.State, elapsed time.Duration) {
+	if state == promise.Fulfilled {
+		m.fulfilled.Add(1)
+	} else {
+		m.rejected.Add(1)
+	}
+	m.settle.Observe(elapsed)
+}
+
+// PromisePanicked 实现 promise.Metrics
+func (m *Metrics) PromisePanicked() {
+	m.panicked.Add(1)
+}
+
+// HandlerDone 实现 promise.Metrics
+func (m *Metrics) HandlerDone(_ string, d time.Duration) {
+	m.handler.Observe(d)
+}
+
+// TaskQueued 实现 promise.Metrics
+func (m *Metrics) TaskQueued() {
+	m.queued.Add(1)
+}
+
+// TaskStarted 实现 promise.Metrics
+func (m *Metrics) TaskStarted() {
+	m.started.Add(1)
+}
+
+// Snapshot 某一时刻的指标快照
+type Snapshot struct {
+	Created   int64 `json:"created"`
+	Fulfilled int64 `json:"fulfilled"`
+	Rejected  int64 `json:"rejected"`
+	Panicked  int64 `json:"panicked"`
+	// Pending 尚未决议的 Promise 数量 (Created - Fulfilled - Rejected)
+	Pending int64 `json:"pending"`
+	// Backlog 已派发但尚未开始执行的任务数量
+	Backlog int64 `json:"dispatch_backlog"`
+
+	SettleLatency  HistogramSnapshot `json:"settle_latency"`
+	HandlerLatency HistogramSnapshot `json:"handler_latency"`
+}
+
+// Snapshot 读取当前指标
+func (m *Metrics) Snapshot() Snapshot {
+	// 先读"完成"类计数再读"开始"类计数，保证并发更新时 Pending / Backlog 不会为负
+	s := Snapshot{
+		Fulfilled: m.fulfilled.Load(),
+		Rejected:  m.rejected.Load(),
+		Panicked:  m.panicked.Load(),
+	}
+	started := m.started.Load()
+	s.Created = m.created.Load()
+	s.Backlog = m.queued.Load() - started
+	s.Pending = s.Created - s.Fulfilled - s.Rejected
+	s.SettleLatency = m.settle.Snapshot()
+	s.HandlerLatency = m.handler.Snapshot()
+	return s
+}
+
+// Publish 以 name 发布到 expvar (同名重复发布会 panic，与 expvar.Publish 一致)
+func (m *Metrics) Publish(name string) {
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		return m.Snapshot()
+	}))
+}
@@ -0,0 +1,180 @@
+package promisemetrics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xigexb/go-promise/promise"
+	"github.com/xigexb/go-promise/promise/promisetest"
+)
+
+func install(t *testing.T) *Metrics {
+	m := New()
+	promise.SetMetrics(m)
+	t.Cleanup(func() { promise.SetMetrics(nil) })
+	return m
+}
+
+func TestMetrics_Counters(t *testing.T) {
+	m := install(t)
+
+	ok := promise.New(func(resolve func(int), reject func(error)) { resolve(1) })
+	bad := promise.New(func(resolve func(int), reject func(error)) { reject(errors.New("x")) })
+	boom := promise.New(func(resolve func(int), reject func(error)) { panic("boom") })
+	_, _ = promise.AllSettled(ok, bad, boom).Await(context.Background())
+
+	s := m.Snapshot()
+	if s.Created != 4 {
+		t.Errorf("created: got %d, want 4", s.Created)
+	}
+	if s.Fulfilled != 2 || s.Rejected != 2 {
+		t.Errorf("settled: got %d fulfilled / %d rejected, want 2 / 2", s.Fulfilled, s.Rejected)
+	}
+	if s.Panicked != 1 {
+		t.Errorf("panicked: got %d, want 1", s.Panicked)
+	}
+	if s.Pending != 0 {
+		t.Errorf("pending: got %d, want 0", s.Pending)
+	}
+	if s.SettleLatency.Count != 4 {
+		t.Errorf("settle histogram count: got %d, want 4", s.SettleLatency.Count)
+	}
+}
+
+func TestMetrics_PendingAndBacklog(t *testing.T) {
+	d, clock := promisetest.Install(t)
+	m := install(t)
+
+	p := promise.Delay(time.Second)
+	promise.New(func(resolve func(int), reject func(error)) { resolve(1) })
+
+	s := m.Snapshot()
+	if s.Backlog != 2 || s.Pending != 2 {
+		t.Fatalf("before run: backlog %d pending %d, want 2 / 2", s.Backlog, s.Pending)
+	}
+
+	d.RunUntilIdle()
+	s = m.Snapshot()
+	if s.Backlog != 0 || s.Pending != 1 {
+		t.Fatalf("after run: backlog %d pending %d, want 0 / 1", s.Backlog, s.Pending)
+	}
+
+	clock.Advance(time.Second)
+	promisetest.AssertFulfilled(t, p, struct{}{})
+	if s = m.Snapshot(); s.Pending != 0 {
+		t.Errorf("after advance: pending %d, want 0", s.Pending)
+	}
+}
+
+func TestMetrics_Handler(t *testing.T) {
+	m := install(t)
+
+	p := promise.Resolve(1).Then(func(v int) int {
+		time.Sleep(2 * time.Millisecond)
+		return v
+	}, nil)
+	_, _ = p.Await(context.Background())
+
+	h := m.Snapshot().HandlerLatency
+	if h.Count != 1 || h.Sum < 0.002 {
+		t.Errorf("handler histogram: count %d sum %v", h.Count, h.Sum)
+	}
+	// 2ms 的样本不应落入 1ms 及以下的桶
+	for _, b := range h.Buckets {
+		if b.UpperBound <= 0.001 && b.Count != 0 {
+			t.Errorf("bucket le=%v should be empty, got %d", b.UpperBound, b.Count)
+		}
+	}
+}
+
+func TestMetrics_Prometheus(t *testing.T) {
+	m := install(t)
+	_, _ = promise.Reject[int](errors.New("x")).Await(context.Background())
+
+	var buf bytes.Buffer
+	if err := m.WritePrometheus(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"# TYPE promise_created_total counter\npromise_created_total 1\n",
+		`promise_settled_total{state="rejected"} 1`,
+		"# TYPE promise_pending gauge\npromise_pending 0\n",
+		`promise_settle_duration_seconds_bucket{le="+Inf"} 1`,
+		"promise_handler_duration_seconds_count 0\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in output:\n%s", want, out)
+		}
+	}
+}
+
+func TestMetrics_Expvar(t *testing.T) {
+	m := install(t)
+	// 使用唯一名称，避免 -count=N 时重复发布导致 panic
+	name := fmt.Sprintf("promise_test_metrics_%d", time.Now().UnixNano())
+	m.Publish(name)
+	_, _ = promise.Resolve(1).Await(context.Background())
+
+	var s Snapshot
+	if err := json.Unmarshal([]byte(expvar.Get(name).String()), &s); err != nil {
+		t.Fatal(err)
+	}
+	if s.Created != 1 || s.Fulfilled != 1 {
+		t.Errorf("expvar snapshot: %+v", s)
+	}
+}
+
+func TestHistogram_SnapshotConsistent(t *testing.T) {
+	h := newHistogram([]float64{0.001, 0.01, 0.1})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 20000; i++ {
+			h.Observe(time.Duration(i%200) * time.Millisecond)
+		}
+	}()
+	for {
+		s := h.Snapshot()
+		// 累计桶必须单调，且不超过 Count (Prometheus 要求 +Inf 桶即 _count)
+		prev := int64(0)
+		for _, b := range s.Buckets {
+			if b.Count < prev || b.Count > s.Count {
+				t.Fatalf("inconsistent snapshot: %+v", s)
+			}
+			prev = b.Count
+		}
+		select {
+		case <-done:
+			return
+		default:
+		}
+	}
+}
+
+func TestMetrics_SwapKeepsPendingPaired(t *testing.T) {
+	old := install(t)
+
+	resolveCh := make(chan func(int), 1)
+	p := promise.New(func(resolve func(int), reject func(error)) { resolveCh <- resolve })
+	resolve := <-resolveCh
+
+	// 替换实例后决议：决议事件仍归属创建时的实例，新实例的 Pending 不会变为负数
+	next := install(t)
+	resolve(1)
+	_, _ = p.Await(context.Background())
+
+	if s := old.Snapshot(); s.Created != 1 || s.Fulfilled != 1 || s.Pending != 0 {
+		t.Errorf("old instance: created %d fulfilled %d pending %d, want 1 / 1 / 0", s.Created, s.Fulfilled, s.Pending)
+	}
+	if s := next.Snapshot(); s.Fulfilled != 0 || s.Pending != 0 {
+		t.Errorf("new instance: fulfilled %d pending %d, want 0 / 0", s.Fulfilled, s.Pending)
+	}
+}
@@ -0,0 +1,62 @@
+package promisemetrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// WritePrometheus 以 Prometheus 文本格式 (0.0.4) 输出当前指标
+func (m *Metrics) WritePrometheus(w io.Writer) error {
+	s := m.Snapshot()
+	bw := bufio.NewWriter(w)
+
+	writeMetric(bw, "promise_created_total", "counter", "Total number of promises created.")
+	fmt.Fprintf(bw, "promise_created_total %d\n", s.Created)
+
+	writeMetric(bw, "promise_settled_total", "counter", "Total number of promises settled, by final state.")
+	fmt.Fprintf(bw, "promise_settled_total{state=\"fulfilled\"} %d\n", s.Fulfilled)
+	fmt.Fprintf(bw, "promise_settled_total{state=\"rejected\"} %d\n", s.Rejected)
+
+	writeMetric(bw, "promise_panics_total", "counter", "Total number of panics recovered in executors and handlers.")
+	fmt.Fprintf(bw, "promise_panics_total %d\n", s.Panicked)
+
+	writeMetric(bw, "promise_pending", "gauge", "Number of promises not yet settled.")
+	fmt.Fprintf(bw, "promise_pending %d\n", s.Pending)
+
+	writeMetric(bw, "promise_dispatch_backlog", "gauge", "Number of dispatched tasks not yet started.")
+	fmt.Fprintf(bw, "promise_dispatch_backlog %d\n", s.Backlog)
+
+	writeHistogram(bw, "promise_settle_duration_seconds", "Time from promise creation to settlement.", s.SettleLatency)
+	writeHistogram(bw, "promise_handler_duration_seconds", "Run time of executors and Then/Finally handlers.", s.HandlerLatency)
+
+	return bw.Flush()
+}
+
+// Handler 返回输出 Prometheus 文本格式的 http.Handler
+func (m *Metrics) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_ = m.WritePrometheus(w)
+	})
+}
+
+func writeMetric(w io.Writer, name, typ, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+func writeHistogram(w io.Writer, name, help string, h HistogramSnapshot) {
+	writeMetric(w, name, "histogram", help)
+	for _, b := range h.Buckets {
+		fmt.Fprintf(w, "%s_bucket{le=\"%s\"} %d\n", name, formatFloat(b.UpperBound), b.Count)
+	}
+	fmt.Fprintf(w, "%s_bucket{le=\"+Inf\"} %d\n", name, h.Count)
+	fmt.Fprintf(w, "%s_sum %s\n", name, formatFloat(h.Sum))
+	fmt.Fprintf(w, "%s_count %d\n", name, h.Count)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
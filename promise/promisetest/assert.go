@@ -0,0 +1,200 @@
+package promisetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xigexb/go-promise/promise"
+)
+
+// AssertFulfilled 断言 p 已经成功且值等于 want (reflect.DeepEqual)
+// 不会等待：p 仍为 Pending 时直接判定失败，需要等待请使用 Eventually
+func AssertFulfilled[T any](t testing.TB, p *promise.Promise[T], want T) bool {
+	t.Helper()
+
+	switch p.GetState() {
+	case promise.Pending:
+		t.Errorf("promise is still pending, want fulfilled with %#v", want)
+		return false
+	case promise.Rejected:
+		_, err := p.Await(context.Background())
+		t.Errorf("promise rejected with %v, want fulfilled with %#v", err, want)
+		return false
+	}
+
+	got, _ := p.Await(context.Background())
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("promise fulfilled with unexpected value:\n%s", diff(got, want))
+		return false
+	}
+	return true
+}
+
+// AssertRejected 断言 p 已经失败且 errors.Is(err, target)
+// target 为 nil 时只检查状态
+func AssertRejected[T any](t testing.TB, p *promise.Promise[T], target error) bool {
+	t.Helper()
+
+	switch p.GetState() {
+	case promise.Pending:
+		t.Errorf("promise is still pending, want rejected with %v", target)
+		return false
+	case promise.Fulfilled:
+		val, _ := p.Await(context.Background())
+		t.Errorf("promise fulfilled with %#v, want rejected with %v", val, target)
+		return false
+	}
+
+	_, err := p.Await(context.Background())
+	if target != nil && !errors.Is(err, target) {
+		t.Errorf("promise rejected with unexpected error:\n got: %v\nwant: %v (errors.Is)", err, target)
+		return false
+	}
+	return true
+}
+
+// AssertPending 断言 p 尚未结束
+func AssertPending[T any](t testing.TB, p *promise.Promise[T]) bool {
+	t.Helper()
+
+	if s := p.GetState(); s != promise.Pending {
+		val, err := p.Await(context.Background())
+		t.Errorf("promise is %v (value %#v, err %v), want pending", s, val, err)
+		return false
+	}
+	return true
+}
+
+// Eventually 等待 p 在 timeout 内结束并返回其结果，超时则判定测试失败
+// 若全局调度器是手动调度器 (Install)，等待期间会持续驱动 RunUntilIdle
+func Eventually[T any](t testing.TB, p *promise.Promise[T], timeout time.Duration) (T, error) {
+	t.Helper()
+
+	deadline := time.Now().Add(timeout)
+	for {
+		if d, ok := manualDispatcher(); ok {
+			d.RunUntilIdle()
+		}
+		if p.GetState() != promise.Pending {
+			return p.Await(context.Background())
+		}
+		if time.Now().After(deadline) {
+			t.Errorf("promise still pending after %v", timeout)
+			return *new(T), context.DeadlineExceeded
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// diff 生成 got / want 的差异描述，定位到第一个不同的字段或下标
+func diff(got, want interface{}) string {
+	var b strings.Builder
+	if path := firstDiff("", reflect.ValueOf(got), reflect.ValueOf(want)); path != "" {
+		fmt.Fprintf(&b, "  first difference at %s\n", path)
+	}
+	fmt.Fprintf(&b, "   got: %#v\n  want: %#v", got, want)
+	return b.String()
+}
+
+func firstDiff(path string, got, want reflect.Value) string {
+	if !got.IsValid() || !want.IsValid() || got.Type() != want.Type() {
+		return describe(path, got, want)
+	}
+
+	switch got.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < got.Len() && i < want.Len(); i++ {
+			if p := firstDiff(fmt.Sprintf("%s[%d]", path, i), got.Index(i), want.Index(i)); p != "" {
+				return p
+			}
+		}
+		if got.Len() != want.Len() {
+			return fmt.Sprintf("%s: len %d, want len %d", orRoot(path), got.Len(), want.Len())
+		}
+		return ""
+	case reflect.Struct:
+		for i := 0; i < got.NumField(); i++ {
+			name := got.Type().Field(i).Name
+			if p := firstDiff(path+"."+name, got.Field(i), want.Field(i)); p != "" {
+				return p
+			}
+		}
+		return ""
+	case reflect.Ptr:
+		if got.IsNil() || want.IsNil() {
+			if got.IsNil() != want.IsNil() {
+				return describe(path, got, want)
+			}
+			return ""
+		}
+		return firstDiff("(*"+orRoot(path)+")", got.Elem(), want.Elem())
+	case reflect.Map:
+		for _, k := range want.MapKeys() {
+			g := got.MapIndex(k)
+			if !g.IsValid() {
+				return fmt.Sprintf("%s[%#v]: missing", orRoot(path), k)
+			}
+			if p := firstDiff(fmt.Sprintf("%s[%#v]", path, k), g, want.MapIndex(k)); p != "" {
+				return p
+			}
+		}
+		for _, k := range got.MapKeys() {
+			if !want.MapIndex(k).IsValid() {
+				return fmt.Sprintf("%s[%#v]: unexpected key", orRoot(path), k)
+			}
+		}
+		return ""
+	}
+
+	if leafEqual(got, want) {
+		return ""
+	}
+	return describe(path, got, want)
+}
+
+// leafEqual 比较标量值，未导出字段无法 Interface() 时按 Kind 读取
+func leafEqual(got, want reflect.Value) bool {
+	if got.CanInterface() && want.CanInterface() {
+		return reflect.DeepEqual(got.Interface(), want.Interface())
+	}
+	switch got.Kind() {
+	case reflect.Bool:
+		return got.Bool() == want.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return got.Int() == want.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return got.Uint() == want.Uint()
+	case reflect.Float32, reflect.Float64:
+		return got.Float() == want.Float()
+	case reflect.Complex64, reflect.Complex128:
+		return got.Complex() == want.Complex()
+	case reflect.String:
+		return got.String() == want.String()
+	}
+	// 其余不可比较的未导出值无法进一步定位，交给外层 DeepEqual 的结论
+	return true
+}
+
+func describe(path string, got, want reflect.Value) string {
+	return fmt.Sprintf("%s: got %s, want %s", orRoot(path), show(got), show(want))
+}
+
+func show(v reflect.Value) string {
+	if !v.IsValid() {
+		return "<nil>"
+	}
+	// fmt 会直接打印 reflect.Value 持有的值 (包括未导出字段)
+	return fmt.Sprintf("%#v", v)
+}
+
+func orRoot(path string) string {
+	if path == "" {
+		return "value"
+	}
+	return path
+}
@@ -0,0 +1,193 @@
+package promisetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xigexb/go-promise/promise"
+)
+
+// recorder 记录断言失败信息而不真正让测试失败
+type recorder struct {
+	testing.TB
+	errors   []string
+	cleanups []func()
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Cleanup(f func()) {
+	r.cleanups = append(r.cleanups, f)
+}
+
+func (r *recorder) finish() {
+	for i := len(r.cleanups) - 1; i >= 0; i-- {
+		r.cleanups[i]()
+	}
+}
+
+func TestAssertFulfilled(t *testing.T) {
+	AssertFulfilled(t, promise.Resolve([]int{1, 2}), []int{1, 2})
+
+	r := &recorder{}
+	AssertFulfilled(r, promise.Resolve([]int{1, 2, 3}), []int{1, 5, 3})
+	if len(r.errors) != 1 || !strings.Contains(r.errors[0], "first difference at [1]: got 2, want 5") {
+		t.Errorf("unexpected failure message: %q", r.errors)
+	}
+}
+
+func TestAssertFulfilled_StructDiff(t *testing.T) {
+	type user struct {
+		ID   int
+		name string
+	}
+	r := &recorder{}
+	AssertFulfilled(r, promise.Resolve(user{1, "a"}), user{1, "b"})
+	if len(r.errors) != 1 || !strings.Contains(r.errors[0], `.name: got "a", want "b"`) {
+		t.Errorf("unexpected failure message: %q", r.errors)
+	}
+}
+
+func TestAssertRejected(t *testing.T) {
+	target := errors.New("boom")
+	AssertRejected(t, promise.Reject[int](fmt.Errorf("wrapped: %w", target)), target)
+
+	r := &recorder{}
+	AssertRejected(r, promise.Resolve(1), target)
+	AssertRejected(r, promise.Reject[int](errors.New("other")), target)
+	if len(r.errors) != 2 {
+		t.Errorf("expected 2 failures, got %q", r.errors)
+	}
+}
+
+func TestAssertPending(t *testing.T) {
+	d, _ := Install(t)
+	p := promise.New(func(resolve func(int), reject func(error)) { resolve(1) })
+	AssertPending(t, p)
+
+	d.RunUntilIdle()
+	r := &recorder{}
+	AssertPending(r, p)
+	if len(r.errors) != 1 {
+		t.Errorf("expected failure for settled promise, got %q", r.errors)
+	}
+}
+
+func TestEventually(t *testing.T) {
+	p := promise.New(func(resolve func(string), reject func(error)) {
+		time.Sleep(5 * time.Millisecond)
+		resolve("ok")
+	})
+	val, err := Eventually(t, p, time.Second)
+	if err != nil || val != "ok" {
+		t.Errorf("expected ok, got %v, %v", val, err)
+	}
+
+	r := &recorder{}
+	never := promise.New(func(resolve func(int), reject func(error)) {})
+	if _, err := Eventually(r, never, 10*time.Millisecond); err == nil || len(r.errors) != 1 {
+		t.Errorf("expected timeout failure, got %v, %q", err, r.errors)
+	}
+}
+
+func TestEventually_PumpsManualDispatcher(t *testing.T) {
+	Install(t)
+	CheckLeaks(t)
+
+	p := promise.Resolve(1).Then(func(v int) int { return v + 1 }, nil)
+	val, _ := Eventually(t, p, time.Second)
+	if val != 2 {
+		t.Errorf("expected 2, got %d", val)
+	}
+}
+
+func TestCheckLeaks(t *testing.T) {
+	prevGrace := LeakGracePeriod
+	LeakGracePeriod = 10 * time.Millisecond
+	defer func() { LeakGracePeriod = prevGrace }()
+
+	Install(t)
+
+	r := &recorder{}
+	CheckLeaks(r)
+	promise.New(func(resolve func(int), reject func(error)) {}) // 从未被执行的任务
+	r.finish()
+
+	if len(r.errors) != 1 || !strings.Contains(r.errors[0], "TestCheckLeaks") {
+		t.Errorf("expected leak report with dispatch site, got %q", r.errors)
+	}
+}
+
+func TestCheckLeaks_ForwardsOptionalInterfaces(t *testing.T) {
+	prev := promise.GlobalDispatcher
+	defer promise.SetDispatcher(prev)
+
+	// 包装后保留内层调度器支持的可选接口，且不会凭空多出内层不支持的接口
+	loop := promise.NewEventLoop()
+	promise.SetDispatcher(loop)
+	r := &recorder{}
+	CheckLeaks(r)
+	if _, ok := promise.GlobalDispatcher.(promise.MicrotaskDispatcher); !ok {
+		t.Error("wrapped EventLoop lost MicrotaskDispatcher")
+	}
+	if _, ok := promise.GlobalDispatcher.(promise.BatchDispatcher); ok {
+		t.Error("wrapped EventLoop must not gain BatchDispatcher")
+	}
+	if _, ok := promise.GlobalDispatcher.(promise.Drainer); !ok {
+		t.Error("wrapped EventLoop lost Drainer")
+	}
+	r.finish()
+
+	// 默认调度器同时支持 BatchDispatcher 与 Drainer
+	promise.SetDispatcher(nil)
+	r = &recorder{}
+	CheckLeaks(r)
+	if _, ok := promise.GlobalDispatcher.(promise.BatchDispatcher); !ok {
+		t.Error("wrapped default dispatcher lost BatchDispatcher")
+	}
+	if _, ok := promise.GlobalDispatcher.(promise.Drainer); !ok {
+		t.Error("wrapped default dispatcher lost Drainer")
+	}
+	r.finish()
+
+	// 手动调度器不支持 Drainer，包装后也不能凭空支持
+	promise.SetDispatcher(NewDispatcher())
+	r = &recorder{}
+	CheckLeaks(r)
+	if _, ok := promise.GlobalDispatcher.(promise.Drainer); ok {
+		t.Error("wrapped manual dispatcher must not gain Drainer")
+	}
+	r.finish()
+
+	pool := promise.NewPriorityPool(1, 0)
+	promise.SetDispatcher(pool)
+	r = &recorder{}
+	CheckLeaks(r)
+	if _, ok := promise.GlobalDispatcher.(promise.PriorityDispatcher); !ok {
+		t.Error("wrapped PriorityPool lost PriorityDispatcher")
+	}
+	if _, ok := promise.GlobalDispatcher.(promise.MicrotaskDispatcher); ok {
+		t.Error("wrapped PriorityPool must not gain MicrotaskDispatcher")
+	}
+
+	// 通过包装排空内层协程池，之后被拒绝的 executor 不计为泄漏
+	if err := promise.GlobalDispatcher.(promise.Drainer).Drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	_, err := promise.New(func(resolve func(int), reject func(error)) { resolve(1) }).Await(context.Background())
+	if !errors.Is(err, promise.ErrDispatcherClosed) {
+		t.Fatalf("expected ErrDispatcherClosed through the wrapper, got %v", err)
+	}
+	r.finish()
+	if len(r.errors) != 0 {
+		t.Errorf("refused task reported as leak: %q", r.errors)
+	}
+}
@@ -0,0 +1,116 @@
+package promisetest
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/xigexb/go-promise/promise"
+)
+
+// defaultStart Install 使用的假时钟起始时间
+var defaultStart = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// FakeClock 假时钟，实现 promise.Clock
+// 时间只有在调用 Advance 时才会前进，到期的定时器回调在 Advance 的调用 Goroutine 中同步执行
+type FakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	seq    uint64
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	clock *FakeClock
+	when  time.Time
+	seq   uint64 // 相同触发时间时按创建顺序执行
+	f     func()
+}
+
+// NewFakeClock 创建起始时间为 start 的假时钟
+func NewFakeClock(start time.Time) *FakeClock {
+	return &FakeClock{now: start}
+}
+
+// Now 实现 promise.Clock
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// AfterFunc 实现 promise.Clock
+func (c *FakeClock) AfterFunc(d time.Duration, f func()) promise.Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.seq++
+	t := &fakeTimer{clock: c, when: c.now.Add(d), seq: c.seq, f: f}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+// Advance 将时间前进 d，并按触发时间顺序执行所有到期的定时器
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	target := c.now.Add(d)
+	c.mu.Unlock()
+
+	for {
+		t := c.popDue(target)
+		if t == nil {
+			break
+		}
+		t.f()
+	}
+
+	c.mu.Lock()
+	c.now = target
+	c.mu.Unlock()
+}
+
+// popDue 取出最早到期 (<= target) 的定时器，并把时钟拨到它的触发时间
+func (c *FakeClock) popDue(target time.Time) *fakeTimer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.timers) == 0 {
+		return nil
+	}
+	sort.Slice(c.timers, func(i, j int) bool {
+		if c.timers[i].when.Equal(c.timers[j].when) {
+			return c.timers[i].seq < c.timers[j].seq
+		}
+		return c.timers[i].when.Before(c.timers[j].when)
+	})
+
+	t := c.timers[0]
+	if t.when.After(target) {
+		return nil
+	}
+	c.timers = c.timers[1:]
+	c.now = t.when
+	return t
+}
+
+// PendingTimers 返回尚未触发的定时器数量
+func (c *FakeClock) PendingTimers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+// Stop 实现 promise.Timer
+func (t *fakeTimer) Stop() bool {
+	c := t.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, other := range c.timers {
+		if other == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
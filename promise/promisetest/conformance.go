@@ -0,0 +1,495 @@
+package promisetest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/xigexb/go-promise/promise"
+)
+
+// conformanceTimeout 单个用例等待 Promise 结束的最长时间
+const conformanceTimeout = 5 * time.Second
+
+// RunConformance 以 Promises/A+ 规范为蓝本，验证库在给定调度器下的行为保证：
+// 单次决议、回调可选 (值/原因透传)、回调至多执行一次、已决议后异步执行回调、
+// Pending 期间注册的回调按 FIFO 顺序执行、结果采纳 (adoption)、Panic 转为拒绝等。
+//
+// newDispatcher 为每个用例创建一个新的调度器，并在用例期间设为全局调度器。
+// 若调度器需要外部驱动 (实现了 RunUntilIdle() int，如手动调度器)，等待期间会自动驱动。
+// 用例会替换包级全局变量，调用方不应在其中使用 t.Parallel。
+func RunConformance(t *testing.T, newDispatcher func() promise.TaskDispatcher) {
+	for _, c := range conformanceCases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			prev := promise.GlobalDispatcher
+			d := newDispatcher()
+			promise.SetDispatcher(d)
+			defer promise.SetDispatcher(prev)
+
+			c.run(t, &env{d: d})
+		})
+	}
+}
+
+type conformanceCase struct {
+	name string
+	run  func(t *testing.T, e *env)
+}
+
+// env 单个用例的运行环境
+type env struct {
+	d promise.TaskDispatcher
+}
+
+// pump 驱动需要外部驱动的调度器
+func (e *env) pump() {
+	if r, ok := e.d.(interface{ RunUntilIdle() int }); ok {
+		r.RunUntilIdle()
+	}
+}
+
+// settle 等待 p 结束并返回结果
+func settle[T any](t *testing.T, e *env, p *promise.Promise[T]) (T, error) {
+	t.Helper()
+
+	deadline := time.Now().Add(conformanceTimeout)
+	for p.GetState() == promise.Pending {
+		e.pump()
+		if p.GetState() != promise.Pending {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("promise did not settle within %v", conformanceTimeout)
+		}
+		time.Sleep(100 * time.Microsecond)
+	}
+	return p.Await(context.Background())
+}
+
+// idle 让调度器运行完当前所有任务 (异步调度器则短暂等待)
+func (e *env) idle() {
+	if isDriven(e) {
+		e.pump()
+		return
+	}
+	time.Sleep(10 * time.Millisecond)
+}
+
+// isDriven 调度器是否在调用方 Goroutine 上被驱动执行 (如手动调度器)
+// 此时回调与注册方处于同一 Goroutine 但仍在 Then 返回之后才执行，goid 判定不适用
+func isDriven(e *env) bool {
+	_, ok := e.d.(interface{ RunUntilIdle() int })
+	return ok
+}
+
+// goid 返回当前 Goroutine ID，仅用于判断回调是否在注册 Goroutine 上同步执行
+func goid() uint64 {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	buf = bytes.TrimPrefix(buf, []byte("goroutine "))
+	buf = buf[:bytes.IndexByte(buf, ' ')]
+	id, _ := strconv.ParseUint(string(buf), 10, 64)
+	return id
+}
+
+var (
+	errReason = errors.New("conformance: reason")
+	errOther  = errors.New("conformance: other")
+)
+
+var conformanceCases = []conformanceCase{
+	// 2.1 状态：只能决议一次
+	{"2.1/fulfilled-cannot-change", func(t *testing.T, e *env) {
+		p := promise.New(func(resolve func(int), reject func(error)) {
+			resolve(1)
+			resolve(2)
+			reject(errReason)
+		})
+		val, err := settle(t, e, p)
+		if err != nil || val != 1 {
+			t.Errorf("expected first resolution 1, got %v, %v", val, err)
+		}
+	}},
+	{"2.1/rejected-cannot-change", func(t *testing.T, e *env) {
+		p := promise.New(func(resolve func(int), reject func(error)) {
+			reject(errReason)
+			reject(errOther)
+			resolve(1)
+		})
+		_, err := settle(t, e, p)
+		if !errors.Is(err, errReason) {
+			t.Errorf("expected first rejection reason, got %v", err)
+		}
+		if p.GetState() != promise.Rejected {
+			t.Errorf("expected rejected, got %v", p.GetState())
+		}
+	}},
+	{"2.1/concurrent-settlement-single-winner", func(t *testing.T, e *env) {
+		p := promise.New(func(resolve func(int), reject func(error)) {
+			var wg sync.WaitGroup
+			for i := 0; i < 16; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					resolve(i)
+				}(i)
+			}
+			wg.Wait()
+		})
+		var calls int32
+		child := p.Then(func(v int) int {
+			atomic.AddInt32(&calls, 1)
+			return v
+		}, nil)
+		settle(t, e, child)
+		e.idle()
+		if n := atomic.LoadInt32(&calls); n != 1 {
+			t.Errorf("handler called %d times, want 1", n)
+		}
+	}},
+
+	// 2.2.1 回调可选：nil 回调透传值与原因
+	{"2.2.1/nil-onFulfilled-passes-value", func(t *testing.T, e *env) {
+		val, err := settle(t, e, promise.Resolve(7).Then(nil, nil))
+		if err != nil || val != 7 {
+			t.Errorf("expected 7, got %v, %v", val, err)
+		}
+	}},
+	{"2.2.1/nil-onRejected-passes-reason", func(t *testing.T, e *env) {
+		_, err := settle(t, e, promise.Reject[int](errReason).Then(func(v int) int { return v }, nil))
+		if !errors.Is(err, errReason) {
+			t.Errorf("expected reason to pass through, got %v", err)
+		}
+	}},
+
+	// 2.2.2 / 2.2.3 回调在决议后执行、参数为值/原因、至多执行一次
+	{"2.2.2/onFulfilled-not-called-before-fulfilled", func(t *testing.T, e *env) {
+		gate := make(chan struct{})
+		var called int32
+		p := promise.New(func(resolve func(int), reject func(error)) {
+			go func() {
+				<-gate
+				resolve(1)
+			}()
+		})
+		child := p.Then(func(v int) int {
+			atomic.AddInt32(&called, 1)
+			return v
+		}, nil)
+		e.idle()
+		if atomic.LoadInt32(&called) != 0 {
+			t.Fatal("onFulfilled called before promise was fulfilled")
+		}
+		close(gate)
+		settle(t, e, child)
+		if n := atomic.LoadInt32(&called); n != 1 {
+			t.Errorf("onFulfilled called %d times, want 1", n)
+		}
+	}},
+	{"2.2.2/onFulfilled-receives-value", func(t *testing.T, e *env) {
+		var got int64
+		settle(t, e, promise.Resolve(42).Then(func(v int) int {
+			atomic.StoreInt64(&got, int64(v))
+			return v
+		}, nil))
+		if atomic.LoadInt64(&got) != 42 {
+			t.Errorf("onFulfilled received %d, want 42", got)
+		}
+	}},
+	{"2.2.3/onRejected-receives-reason-once", func(t *testing.T, e *env) {
+		var calls int32
+		var got atomic.Value
+		child := promise.New(func(resolve func(int), reject func(error)) {
+			reject(errReason)
+			reject(errOther)
+		}).Then(nil, func(err error) error {
+			atomic.AddInt32(&calls, 1)
+			got.Store(err)
+			return err
+		})
+		settle(t, e, child)
+		e.idle()
+		if n := atomic.LoadInt32(&calls); n != 1 {
+			t.Errorf("onRejected called %d times, want 1", n)
+		}
+		if err, _ := got.Load().(error); !errors.Is(err, errReason) {
+			t.Errorf("onRejected received %v, want %v", got.Load(), errReason)
+		}
+	}},
+	{"2.2.3/onFulfilled-not-called-on-rejection", func(t *testing.T, e *env) {
+		var called int32
+		settle(t, e, promise.Reject[int](errReason).Then(func(v int) int {
+			atomic.AddInt32(&called, 1)
+			return v
+		}, nil))
+		if atomic.LoadInt32(&called) != 0 {
+			t.Error("onFulfilled called for rejected promise")
+		}
+	}},
+
+	// 2.2.4 已决议的 Promise 上注册回调，不得在 Then 调用栈中同步执行
+	{"2.2.4/async-after-settle", func(t *testing.T, e *env) {
+		caller := goid()
+		var handlerG uint64
+		var called int32
+		child := promise.Resolve(1).Then(func(v int) int {
+			atomic.StoreUint64(&handlerG, goid())
+			atomic.AddInt32(&called, 1)
+			return v
+		}, nil)
+
+		if isDriven(e) {
+			// 外部驱动的调度器：Then 返回时回调必须尚未执行
+			if atomic.LoadInt32(&called) != 0 {
+				t.Fatal("handler ran synchronously inside Then")
+			}
+			settle(t, e, child)
+			return
+		}
+		settle(t, e, child)
+		if atomic.LoadUint64(&handlerG) == caller {
+			t.Error("handler ran synchronously inside Then")
+		}
+	}},
+
+	// 2.2.6 Pending 期间多次注册的回调按注册顺序执行
+	{"2.2.6/fifo-handler-order", func(t *testing.T, e *env) {
+		gate := make(chan struct{})
+		p := promise.New(func(resolve func(int), reject func(error)) {
+			go func() {
+				<-gate
+				resolve(1)
+			}()
+		})
+		var mu sync.Mutex
+		var order []int
+		children := make([]*promise.Promise[int], 5)
+		for i := range children {
+			i := i
+			children[i] = p.Then(func(v int) int {
+				mu.Lock()
+				order = append(order, i)
+				mu.Unlock()
+				return v
+			}, nil)
+		}
+		e.idle()
+		close(gate)
+		for _, c := range children {
+			settle(t, e, c)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		for i, v := range order {
+			if v != i {
+				t.Fatalf("handlers ran in order %v, want FIFO", order)
+			}
+		}
+	}},
+	{"2.2.6/fifo-rejection-order", func(t *testing.T, e *env) {
+		gate := make(chan struct{})
+		p := promise.New(func(resolve func(int), reject func(error)) {
+			go func() {
+				<-gate
+				reject(errReason)
+			}()
+		})
+		var mu sync.Mutex
+		var order []int
+		children := make([]*promise.Promise[int], 5)
+		for i := range children {
+			i := i
+			children[i] = p.Catch(func(err error) error {
+				mu.Lock()
+				order = append(order, i)
+				mu.Unlock()
+				return err
+			})
+		}
+		e.idle()
+		close(gate)
+		for _, c := range children {
+			settle(t, e, c)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		for i, v := range order {
+			if v != i {
+				t.Fatalf("handlers ran in order %v, want FIFO", order)
+			}
+		}
+	}},
+	{"2.2.6/handler-registered-inside-handler", func(t *testing.T, e *env) {
+		p := promise.Resolve(1)
+		inner := make(chan *promise.Promise[int], 1)
+		settle(t, e, p.Then(func(v int) int {
+			inner <- p.Then(func(v int) int { return v + 1 }, nil)
+			return v
+		}, nil))
+		val, err := settle(t, e, <-inner)
+		if err != nil || val != 2 {
+			t.Errorf("nested registration: expected 2, got %v, %v", val, err)
+		}
+	}},
+
+	// 2.2.7 Then 返回新的 Promise
+	{"2.2.7/then-returns-new-promise", func(t *testing.T, e *env) {
+		p := promise.Resolve(1)
+		child := p.Then(func(v int) int { return v + 1 }, nil)
+		if child == p {
+			t.Fatal("Then must return a new promise")
+		}
+		val, err := settle(t, e, child)
+		if err != nil || val != 2 {
+			t.Errorf("expected 2, got %v, %v", val, err)
+		}
+	}},
+	{"2.2.7/onRejected-result-rejects-child", func(t *testing.T, e *env) {
+		_, err := settle(t, e, promise.Reject[int](errReason).Catch(func(err error) error {
+			return errOther
+		}))
+		if !errors.Is(err, errOther) {
+			t.Errorf("expected child rejected with handler result, got %v", err)
+		}
+	}},
+	{"2.2.7/onFulfilled-panic-rejects-child", func(t *testing.T, e *env) {
+		_, err := settle(t, e, promise.Resolve(1).Then(func(v int) int {
+			panic(errReason)
+		}, nil))
+		if !errors.Is(err, errReason) {
+			t.Errorf("expected panic value as reason, got %v", err)
+		}
+	}},
+	{"2.2.7/onRejected-panic-rejects-child", func(t *testing.T, e *env) {
+		_, err := settle(t, e, promise.Reject[int](errOther).Catch(func(err error) error {
+			panic("handler exploded")
+		}))
+		if err == nil || !strings.HasPrefix(err.Error(), "panic: handler exploded") {
+			t.Errorf("expected panic converted to rejection, got %v", err)
+		}
+	}},
+	{"2.2.7/rejection-propagates-through-chain", func(t *testing.T, e *env) {
+		p := promise.Reject[int](errReason).
+			Then(func(v int) int { return v + 1 }, nil).
+			Then(func(v int) int { return v + 1 }, nil)
+		if _, err := settle(t, e, p); !errors.Is(err, errReason) {
+			t.Errorf("expected reason to bubble, got %v", err)
+		}
+	}},
+
+	// 2.3 结果采纳：派生 Promise 采纳来源 Promise 的最终状态
+	{"2.3/race-adopts-fulfillment", func(t *testing.T, e *env) {
+		src := promise.New(func(resolve func(int), reject func(error)) { resolve(9) })
+		val, err := settle(t, e, promise.Race(src))
+		if err != nil || val != 9 {
+			t.Errorf("expected adopted value 9, got %v, %v", val, err)
+		}
+	}},
+	{"2.3/race-adopts-rejection-identity", func(t *testing.T, e *env) {
+		src := promise.New(func(resolve func(int), reject func(error)) { reject(errReason) })
+		if _, err := settle(t, e, promise.Race(src)); !errors.Is(err, errReason) {
+			t.Errorf("expected adopted reason, got %v", err)
+		}
+	}},
+	{"2.3/map-adopts-and-transforms", func(t *testing.T, e *env) {
+		m := promise.Map(promise.Resolve(2), func(v int) (string, error) {
+			return strconv.Itoa(v * 2), nil
+		})
+		val, err := settle(t, e, m)
+		if err != nil || val != "4" {
+			t.Errorf("expected \"4\", got %q, %v", val, err)
+		}
+		if _, err := settle(t, e, promise.Map(promise.Reject[int](errReason), func(v int) (string, error) {
+			return "", nil
+		})); !errors.Is(err, errReason) {
+			t.Errorf("expected Map to adopt rejection, got %v", err)
+		}
+	}},
+
+	// Panic 转为拒绝
+	{"panic/executor-string", func(t *testing.T, e *env) {
+		_, err := settle(t, e, promise.New(func(resolve func(int), reject func(error)) {
+			panic("boom")
+		}))
+		if err == nil || !strings.HasPrefix(err.Error(), "panic: boom") {
+			t.Errorf("expected panic: boom, got %v", err)
+		}
+	}},
+	{"panic/executor-error-identity", func(t *testing.T, e *env) {
+		_, err := settle(t, e, promise.New(func(resolve func(int), reject func(error)) {
+			panic(errReason)
+		}))
+		if !errors.Is(err, errReason) {
+			t.Errorf("expected panic error value as reason, got %v", err)
+		}
+	}},
+	{"panic/after-resolve-ignored", func(t *testing.T, e *env) {
+		val, err := settle(t, e, promise.New(func(resolve func(int), reject func(error)) {
+			resolve(1)
+			panic("late")
+		}))
+		if err != nil || val != 1 {
+			t.Errorf("panic after resolve must not change outcome, got %v, %v", val, err)
+		}
+	}},
+	{"panic/finally", func(t *testing.T, e *env) {
+		_, err := settle(t, e, promise.Resolve(1).Finally(func() { panic("cleanup") }))
+		if err == nil || !strings.HasPrefix(err.Error(), "panic: cleanup") {
+			t.Errorf("expected Finally panic to reject, got %v", err)
+		}
+	}},
+
+	// Finally 透传结果
+	{"finally/passes-value-and-reason", func(t *testing.T, e *env) {
+		var runs int32
+		val, err := settle(t, e, promise.Resolve(3).Finally(func() { atomic.AddInt32(&runs, 1) }))
+		if err != nil || val != 3 {
+			t.Errorf("Finally changed value: %v, %v", val, err)
+		}
+		if _, err := settle(t, e, promise.Reject[int](errReason).Finally(func() { atomic.AddInt32(&runs, 1) })); !errors.Is(err, errReason) {
+			t.Errorf("Finally changed reason: %v", err)
+		}
+		if n := atomic.LoadInt32(&runs); n != 2 {
+			t.Errorf("Finally ran %d times, want 2", n)
+		}
+	}},
+
+	// Await：多个等待者得到相同结果
+	{"await/multiple-waiters", func(t *testing.T, e *env) {
+		gate := make(chan struct{})
+		p := promise.New(func(resolve func(int), reject func(error)) {
+			go func() {
+				<-gate
+				resolve(5)
+			}()
+		})
+		e.idle()
+		results := make(chan int, 3)
+		for i := 0; i < 3; i++ {
+			go func() {
+				val, _ := p.Await(context.Background())
+				results <- val
+			}()
+		}
+		close(gate)
+		settle(t, e, p)
+		for i := 0; i < 3; i++ {
+			select {
+			case v := <-results:
+				if v != 5 {
+					t.Errorf("waiter got %d, want 5", v)
+				}
+			case <-time.After(conformanceTimeout):
+				t.Fatal("waiter not woken")
+			}
+		}
+	}},
+}
@@ -0,0 +1,40 @@
+package promisetest
+
+import (
+	"testing"
+
+	"github.com/xigexb/go-promise/promise"
+)
+
+// goDispatcher 与默认调度器相同：每个任务一个 Goroutine
+type goDispatcher struct{}
+
+func (goDispatcher) Dispatch(f func()) {
+	go f()
+}
+
+func TestConformance_Goroutine(t *testing.T) {
+	RunConformance(t, func() promise.TaskDispatcher { return goDispatcher{} })
+}
+
+func TestConformance_Manual(t *testing.T) {
+	RunConformance(t, func() promise.TaskDispatcher { return NewDispatcher() })
+}
+
+func TestConformance_EventLoop(t *testing.T) {
+	RunConformance(t, func() promise.TaskDispatcher { return promise.NewEventLoop() })
+}
+
+func TestConformance_PriorityPool(t *testing.T) {
+	var pools []*promise.PriorityPool
+	defer func() {
+		for _, p := range pools {
+			p.Close()
+		}
+	}()
+	RunConformance(t, func() promise.TaskDispatcher {
+		p := promise.NewPriorityPool(4, 0)
+		pools = append(pools, p)
+		return p
+	})
+}
@@ -0,0 +1,83 @@
+// Package promisetest 提供 go-promise 的测试辅助工具：
+// 手动调度器 (Dispatcher) 与假时钟 (FakeClock)，让基于 New / Delay / Timeout 的代码在测试中确定性执行；
+// 以及 AssertFulfilled / AssertRejected / AssertPending / Eventually 等断言和 CheckLeaks 任务泄漏检查。
+// RunConformance 是可针对任意 TaskDispatcher 运行的行为一致性测试套件。
+package promisetest
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/xigexb/go-promise/promise"
+)
+
+// Dispatcher 手动调度器
+// Dispatch 只把任务放入队列，由测试代码通过 Step / RunUntilIdle 在当前 Goroutine 中执行
+type Dispatcher struct {
+	mu    sync.Mutex
+	queue []func()
+}
+
+// NewDispatcher 创建手动调度器
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{}
+}
+
+// Dispatch 实现 promise.TaskDispatcher 接口
+func (d *Dispatcher) Dispatch(f func()) {
+	d.mu.Lock()
+	d.queue = append(d.queue, f)
+	d.mu.Unlock()
+}
+
+// Step 执行队列中最早的一个任务，队列为空时返回 false
+func (d *Dispatcher) Step() bool {
+	d.mu.Lock()
+	if len(d.queue) == 0 {
+		d.mu.Unlock()
+		return false
+	}
+	f := d.queue[0]
+	d.queue[0] = nil
+	d.queue = d.queue[1:]
+	d.mu.Unlock()
+
+	f()
+	return true
+}
+
+// RunUntilIdle 持续执行任务 (包括执行过程中新产生的任务) 直到队列为空，返回执行的任务数
+func (d *Dispatcher) RunUntilIdle() int {
+	n := 0
+	for d.Step() {
+		n++
+	}
+	return n
+}
+
+// Pending 返回队列中尚未执行的任务数
+func (d *Dispatcher) Pending() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.queue)
+}
+
+// Install 将手动调度器和假时钟设置为全局调度器/时钟，测试结束时自动恢复
+// 替换的是包级全局变量，因此使用 Install 的测试不能并行 (t.Parallel) 运行
+func Install(t testing.TB) (*Dispatcher, *FakeClock) {
+	t.Helper()
+
+	prevDispatcher := promise.GlobalDispatcher
+	prevClock := promise.GlobalClock
+
+	d := NewDispatcher()
+	c := NewFakeClock(defaultStart)
+	promise.SetDispatcher(d)
+	promise.SetClock(c)
+
+	t.Cleanup(func() {
+		promise.SetDispatcher(prevDispatcher)
+		promise.SetClock(prevClock)
+	})
+	return d, c
+}
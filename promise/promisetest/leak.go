@@ -0,0 +1,234 @@
+package promisetest
+
+import (
+	"context"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/xigexb/go-promise/promise"
+)
+
+// LeakGracePeriod CheckLeaks 在测试结束后等待任务自然退出的最长时间
+var LeakGracePeriod = time.Second
+
+// trackingDispatcher 包装另一个调度器，记录尚未执行完毕的任务及其派发位置
+// 转发 Dispatcher / PriorityDispatcher (内层不支持时退回 Dispatch，与库的行为一致)；
+// MicrotaskDispatcher / BatchDispatcher 会改变回调的执行方式，Drainer 会被调用方用来判断能否排空，
+// 三者只在内层支持时通过 wrapTracking 选择的包装类型暴露。
+type trackingDispatcher struct {
+	inner promise.TaskDispatcher
+
+	mu       sync.Mutex
+	nextID   uint64
+	inflight map[uint64]string
+}
+
+func (d *trackingDispatcher) Dispatch(f func()) {
+	run, _ := d.track(f)
+	d.inner.Dispatch(run)
+}
+
+// DispatchTask 实现 promise.Dispatcher，内层拒绝时撤销记录并返回其错误
+func (d *trackingDispatcher) DispatchTask(t promise.Task) error {
+	run, untrack := d.track(t.Run)
+	inner, ok := d.inner.(promise.Dispatcher)
+	if !ok {
+		d.inner.Dispatch(run)
+		return nil
+	}
+	t.Run = run
+	if err := inner.DispatchTask(t); err != nil {
+		untrack()
+		return err
+	}
+	return nil
+}
+
+// DispatchPriority 实现 promise.PriorityDispatcher
+func (d *trackingDispatcher) DispatchPriority(p promise.Priority, f func()) {
+	run, _ := d.track(f)
+	if inner, ok := d.inner.(promise.PriorityDispatcher); ok {
+		inner.DispatchPriority(p, run)
+		return
+	}
+	d.inner.Dispatch(run)
+}
+
+// track 记录任务的派发位置，返回执行完毕时移除记录的包装函数，以及任务被拒绝时撤销记录的函数
+func (d *trackingDispatcher) track(f func()) (run func(), untrack func()) {
+	site := dispatchSite()
+
+	d.mu.Lock()
+	d.nextID++
+	id := d.nextID
+	d.inflight[id] = site
+	d.mu.Unlock()
+
+	untrack = func() {
+		d.mu.Lock()
+		delete(d.inflight, id)
+		d.mu.Unlock()
+	}
+	return func() {
+		defer untrack()
+		f()
+	}, untrack
+}
+
+func (d *trackingDispatcher) tracking() *trackingDispatcher {
+	return d
+}
+
+// trackingMicrotask 内层实现 MicrotaskDispatcher 时使用 (如 EventLoop)
+type trackingMicrotask struct{ *trackingDispatcher }
+
+func (d trackingMicrotask) DispatchMicrotask(f func()) {
+	run, _ := d.track(f)
+	d.inner.(promise.MicrotaskDispatcher).DispatchMicrotask(run)
+}
+
+// trackingBatch 内层实现 BatchDispatcher 时使用
+type trackingBatch struct{ *trackingDispatcher }
+
+func (d trackingBatch) DispatchBatch(tasks []func()) {
+	d.dispatchBatch(tasks)
+}
+
+// trackingMicrotaskBatch 内层同时实现 MicrotaskDispatcher 与 BatchDispatcher 时使用
+type trackingMicrotaskBatch struct{ trackingMicrotask }
+
+func (d trackingMicrotaskBatch) DispatchBatch(tasks []func()) {
+	d.dispatchBatch(tasks)
+}
+
+func (d *trackingDispatcher) dispatchBatch(tasks []func()) {
+	wrapped := make([]func(), len(tasks))
+	for i, f := range tasks {
+		wrapped[i], _ = d.track(f)
+	}
+	d.inner.(promise.BatchDispatcher).DispatchBatch(wrapped)
+}
+
+// 以下包装类型在内层实现 promise.Drainer 时使用，额外转发 Drain
+type (
+	trackingDrain               struct{ *trackingDispatcher }
+	trackingMicrotaskDrain      struct{ trackingMicrotask }
+	trackingBatchDrain          struct{ trackingBatch }
+	trackingMicrotaskBatchDrain struct{ trackingMicrotaskBatch }
+)
+
+func (d trackingDrain) Drain(ctx context.Context) error               { return d.drain(ctx) }
+func (d trackingMicrotaskDrain) Drain(ctx context.Context) error      { return d.drain(ctx) }
+func (d trackingBatchDrain) Drain(ctx context.Context) error          { return d.drain(ctx) }
+func (d trackingMicrotaskBatchDrain) Drain(ctx context.Context) error { return d.drain(ctx) }
+
+func (d *trackingDispatcher) drain(ctx context.Context) error {
+	return d.inner.(promise.Drainer).Drain(ctx)
+}
+
+// wrapTracking 按内层调度器支持的可选接口选择包装类型
+func wrapTracking(d *trackingDispatcher) promise.TaskDispatcher {
+	_, micro := d.inner.(promise.MicrotaskDispatcher)
+	_, batch := d.inner.(promise.BatchDispatcher)
+	_, drain := d.inner.(promise.Drainer)
+	switch {
+	case micro && batch && drain:
+		return trackingMicrotaskBatchDrain{trackingMicrotaskBatch{trackingMicrotask{d}}}
+	case micro && batch:
+		return trackingMicrotaskBatch{trackingMicrotask{d}}
+	case micro && drain:
+		return trackingMicrotaskDrain{trackingMicrotask{d}}
+	case micro:
+		return trackingMicrotask{d}
+	case batch && drain:
+		return trackingBatchDrain{trackingBatch{d}}
+	case batch:
+		return trackingBatch{d}
+	case drain:
+		return trackingDrain{d}
+	default:
+		return d
+	}
+}
+
+// manualDispatcher 返回当前生效的手动调度器 (穿透 CheckLeaks 的包装)
+func manualDispatcher() (*Dispatcher, bool) {
+	switch d := promise.GlobalDispatcher.(type) {
+	case *Dispatcher:
+		return d, true
+	case interface{ tracking() *trackingDispatcher }:
+		m, ok := d.tracking().inner.(*Dispatcher)
+		return m, ok
+	}
+	return nil, false
+}
+
+func (d *trackingDispatcher) snapshot() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	sites := make([]string, 0, len(d.inflight))
+	for _, site := range d.inflight {
+		sites = append(sites, site)
+	}
+	sort.Strings(sites)
+	return sites
+}
+
+// CheckLeaks 在测试期间跟踪所有通过全局调度器派发的任务
+// 测试结束时若仍有任务在运行 (或仍留在手动调度器队列中)，在 LeakGracePeriod 后判定测试失败并列出派发位置。
+// 与 Install 同时使用时应在 Install 之后调用。
+func CheckLeaks(t testing.TB) {
+	t.Helper()
+
+	prev := promise.GlobalDispatcher
+	tracker := &trackingDispatcher{inner: prev, inflight: make(map[uint64]string)}
+	promise.SetDispatcher(wrapTracking(tracker))
+
+	t.Cleanup(func() {
+		defer promise.SetDispatcher(prev)
+
+		deadline := time.Now().Add(LeakGracePeriod)
+		for {
+			leaked := tracker.snapshot()
+			if len(leaked) == 0 {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Errorf("%d dispatched task(s) still running after test:\n  %s",
+					len(leaked), strings.Join(leaked, "\n  "))
+				return
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	})
+}
+
+// dispatchSite 找到派发任务的调用方 (跳过 promise 库自身的栈帧)
+func dispatchSite() string {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !isLibraryFrame(frame) {
+			return frame.Function + " (" + frame.File + ":" + strconv.Itoa(frame.Line) + ")"
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
+
+func isLibraryFrame(frame runtime.Frame) bool {
+	if strings.HasSuffix(frame.File, "_test.go") {
+		return false
+	}
+	return strings.HasPrefix(frame.Function, "github.com/xigexb/go-promise/promise.") ||
+		strings.HasPrefix(frame.Function, "github.com/xigexb/go-promise/promise/promisetest.")
+}
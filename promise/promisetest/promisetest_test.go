@@ -0,0 +1,86 @@
+package promisetest
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xigexb/go-promise/promise"
+)
+
+func TestDispatcher_FIFO(t *testing.T) {
+	d := NewDispatcher()
+	var order []int
+	d.Dispatch(func() {
+		order = append(order, 1)
+		d.Dispatch(func() { order = append(order, 3) })
+	})
+	d.Dispatch(func() { order = append(order, 2) })
+
+	if n := d.RunUntilIdle(); n != 3 {
+		t.Fatalf("expected 3 tasks, ran %d", n)
+	}
+	if order[0] != 1 || order[1] != 2 || order[2] != 3 {
+		t.Errorf("unexpected order %v", order)
+	}
+}
+
+func TestDelay_FakeClock(t *testing.T) {
+	d, clock := Install(t)
+
+	p := promise.Delay(time.Hour)
+	d.RunUntilIdle()
+	if p.GetState() != promise.Pending {
+		t.Fatal("delay settled before time advanced")
+	}
+
+	clock.Advance(59 * time.Minute)
+	if p.GetState() != promise.Pending {
+		t.Fatal("delay settled too early")
+	}
+
+	clock.Advance(time.Minute)
+	if p.GetState() != promise.Fulfilled {
+		t.Fatalf("expected fulfilled, got %v", p.GetState())
+	}
+}
+
+func TestTimeout_FakeClock(t *testing.T) {
+	d, clock := Install(t)
+
+	slow := promise.New(func(resolve func(int), reject func(error)) {
+		clock.AfterFunc(10*time.Second, func() { resolve(1) })
+	})
+	p := slow.Timeout(time.Second, "too slow")
+	d.RunUntilIdle()
+
+	clock.Advance(time.Second)
+	d.RunUntilIdle()
+
+	_, err := p.Await(context.Background())
+	if err == nil || !strings.HasPrefix(err.Error(), "too slow") {
+		t.Fatalf("expected timeout error, got %v", err)
+	}
+}
+
+func TestTimeout_FakeClock_SettlesFirst(t *testing.T) {
+	d, clock := Install(t)
+
+	fast := promise.New(func(resolve func(int), reject func(error)) {
+		clock.AfterFunc(time.Millisecond, func() { resolve(7) })
+	})
+	p := fast.Timeout(time.Second, "")
+	d.RunUntilIdle()
+
+	clock.Advance(time.Millisecond)
+	d.RunUntilIdle()
+
+	val, err := p.Await(context.Background())
+	if err != nil || val != 7 {
+		t.Fatalf("expected 7, got %v, %v", val, err)
+	}
+	if n := clock.PendingTimers(); n != 0 {
+		t.Errorf("timeout timer should be stopped, %d pending", n)
+	}
+}
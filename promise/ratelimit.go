@@ -0,0 +1,244 @@
+package promise
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Limiter 限流器接口
+// Wait 阻塞直到拿到一个令牌，或 ctx 结束时返回 ctx.Err()
+type Limiter interface {
+	Wait(ctx context.Context) error
+}
+
+// RateLimitError RateLimited 未能启动 factory 时的拒绝原因：
+// 等待令牌期间 (或之前) Context 结束，或调度器拒绝派发启动任务
+type RateLimitError struct {
+	Err error // 底层原因：ctx.Err() 或调度器返回的错误
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("rate limit: wait aborted: %v", e.Err)
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return e.Err
+}
+
+// TokenBucket 令牌桶限流器
+// 以 rate 个/秒的速度补充令牌，最多积攒 burst 个
+type TokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewTokenBucket 创建令牌桶，初始为满桶
+func NewTokenBucket(rate float64, burst int) *TokenBucket {
+	if rate <= 0 {
+		panic("promise: token bucket rate must be positive")
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &TokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   GlobalClock.Now(),
+	}
+}
+
+// refill 按流逝时间补充令牌 (调用方需持有锁)
+func (b *TokenBucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens += elapsed.Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+	}
+	b.last = now
+}
+
+// reserve 预占一个令牌，返回需要等待的时长
+func (b *TokenBucket) reserve() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(GlobalClock.Now())
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// cancel 归还一个预占但未使用的令牌
+func (b *TokenBucket) cancel() {
+	b.mu.Lock()
+	b.tokens++
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.mu.Unlock()
+}
+
+// Allow 非阻塞地尝试获取令牌
+func (b *TokenBucket) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(GlobalClock.Now())
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// Wait 实现 Limiter 接口
+func (b *TokenBucket) Wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	wait := b.reserve()
+	if wait == 0 {
+		return nil
+	}
+
+	fired := make(chan struct{})
+	timer := GlobalClock.AfterFunc(wait, func() { close(fired) })
+	defer timer.Stop()
+
+	select {
+	case <-fired:
+		return nil
+	case <-ctx.Done():
+		b.cancel()
+		return ctx.Err()
+	}
+}
+
+// Reserver 可预占令牌的限流器 (可选接口)
+// Reserve 立即预占一个令牌并返回还需等待的时长，cancel 归还未使用的令牌。
+// RateLimited 对实现该接口的限流器 (如 TokenBucket) 使用 GlobalClock 定时器等待，不占用调度器的 worker。
+type Reserver interface {
+	Reserve() (wait time.Duration, cancel func())
+}
+
+// Reserve 实现 Reserver
+func (b *TokenBucket) Reserve() (time.Duration, func()) {
+	return b.reserve(), b.cancel
+}
+
+// RateLimited 包装 Promise 工厂，每次调用都先从 limiter 获取令牌再启动 factory
+// factory 未能启动 (ctx 已结束、等待期间结束或调度器拒绝派发) 时总是以 *RateLimitError 拒绝。
+// 返回值仍是工厂函数，可直接用于扇出 (fan-out) 场景而无需调用方自行处理定时器。
+// limiter 实现 Reserver 时通过 GlobalClock 定时器等待令牌；否则只能在派发的任务中阻塞调用 Wait，
+// 会占住一个 worker，不能与 EventLoop 一起使用。
+func RateLimited[T any](limiter Limiter, factory func(context.Context) *Promise[T]) func(context.Context) *Promise[T] {
+	return func(ctx context.Context) *Promise[T] {
+		p := &Promise[T]{}
+		p.observe(ctx, "RateLimited", nil)
+
+		// launch 派发 f，ctx 已结束或调度器拒绝时返回 false 并以 *RateLimitError 拒绝 p
+		launch := func(f func()) bool {
+			err := ctx.Err()
+			if err == nil {
+				err = p.dispatchFactory(ctx, f)
+			}
+			if err != nil {
+				p.Reject(&RateLimitError{Err: err})
+				return false
+			}
+			return true
+		}
+
+		r, ok := limiter.(Reserver)
+		if !ok {
+			launch(func() {
+				if err := limiter.Wait(ctx); err != nil {
+					p.Reject(&RateLimitError{Err: err})
+					return
+				}
+				follow(p, factory(ctx))
+			})
+			return p
+		}
+
+		if err := ctx.Err(); err != nil {
+			p.Reject(&RateLimitError{Err: err})
+			return p
+		}
+		wait, cancel := r.Reserve()
+		start := func() {
+			if !launch(func() { follow(p, factory(ctx)) }) {
+				cancel() // factory 不会运行，归还令牌
+			}
+		}
+		if wait <= 0 {
+			start()
+			return p
+		}
+
+		// 定时器与 ctx 竞争：先到者胜出，mu 保证两个回调看到的都是已初始化的 timer / stop
+		var (
+			mu    sync.Mutex
+			done  bool
+			timer Timer
+			stop  func() bool
+		)
+		claim := func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			if done {
+				return false
+			}
+			done = true
+			return true
+		}
+
+		mu.Lock()
+		timer = GlobalClock.AfterFunc(wait, func() {
+			if claim() {
+				stop()
+				start()
+			}
+		})
+		stop = context.AfterFunc(ctx, func() {
+			if claim() {
+				timer.Stop()
+				cancel()
+				p.Reject(&RateLimitError{Err: ctx.Err()})
+			}
+		})
+		mu.Unlock()
+
+		return p
+	}
+}
+
+// dispatchFactory 派发启动工厂函数的任务 (f 中的 Panic 会拒绝 p)
+// 返回调度器拒绝的原因，此时 f 不会执行，由调用方决定如何拒绝 p
+func (p *Promise[T]) dispatchFactory(ctx context.Context, f func()) error {
+	return dispatch(ctx, p.obs, p.priority, func() {
+		defer handlePanic(p.obs, p.Reject)
+		f()
+	})
+}
+
+// follow 让 p 采纳 src 的最终结果
+func follow[T any](p *Promise[T], src *Promise[T]) {
+	attachHandler(src, func() {
+		if src.GetState() == Fulfilled {
+			p.Resolve(src.val)
+		} else {
+			p.rejectPropagated(src.err)
+		}
+	})
+}
@@ -0,0 +1,156 @@
+package promise
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTokenBucket_Burst(t *testing.T) {
+	b := NewTokenBucket(1, 3)
+	for i := 0; i < 3; i++ {
+		if !b.Allow() {
+			t.Fatalf("token %d should be available within burst", i)
+		}
+	}
+	if b.Allow() {
+		t.Error("bucket should be empty after burst")
+	}
+}
+
+func TestRateLimited_Throttle(t *testing.T) {
+	// 100/s, burst 1: 第 2、3 个任务各需等待约 10ms
+	limiter := NewTokenBucket(100, 1)
+	var started int32
+	fetch := RateLimited(limiter, func(ctx context.Context) *Promise[int] {
+		n := atomic.AddInt32(&started, 1)
+		return Resolve(int(n))
+	})
+
+	begin := time.Now()
+	ps := []*Promise[int]{
+		fetch(context.Background()),
+		fetch(context.Background()),
+		fetch(context.Background()),
+	}
+	if _, err := All(ps...).Await(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if elapsed := time.Since(begin); elapsed < 15*time.Millisecond {
+		t.Errorf("expected throttling, all finished in %v", elapsed)
+	}
+	assertEqual(t, int32(3), atomic.LoadInt32(&started), "factory calls")
+}
+
+func TestRateLimited_ContextExpired(t *testing.T) {
+	limiter := NewTokenBucket(0.1, 1)
+	limiter.Allow() // 取走唯一的令牌
+
+	var called int32
+	fetch := RateLimited(limiter, func(ctx context.Context) *Promise[int] {
+		atomic.AddInt32(&called, 1)
+		return Resolve(1)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := fetch(ctx).Await(context.Background())
+
+	var rlErr *RateLimitError
+	if !errors.As(err, &rlErr) {
+		t.Fatalf("expected *RateLimitError, got %v", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected wrapped DeadlineExceeded, got %v", rlErr.Err)
+	}
+	assertEqual(t, int32(0), atomic.LoadInt32(&called), "factory must not run")
+}
+
+func TestRateLimited_FactoryRejects(t *testing.T) {
+	expected := errors.New("quota")
+	fetch := RateLimited(NewTokenBucket(1000, 1), func(ctx context.Context) *Promise[int] {
+		return Reject[int](expected)
+	})
+
+	_, err := fetch(context.Background()).Await(context.Background())
+	if !errors.Is(err, expected) {
+		t.Errorf("factory rejection: expected %v, got %v", expected, err)
+	}
+}
+
+func TestRateLimited_EventLoop(t *testing.T) {
+	loop := NewEventLoop()
+	setGlobal[TaskDispatcher](t, SetDispatcher, GlobalDispatcher, loop)
+	setGlobal[Clock](t, SetClock, GlobalClock, loop)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = loop.Run(ctx) }()
+
+	// 等待令牌不能阻塞事件循环：定时器回调同样需要事件循环执行
+	fetch := RateLimited(NewTokenBucket(100, 1), func(ctx context.Context) *Promise[int] {
+		return Resolve(1)
+	})
+	all := All(fetch(ctx), fetch(ctx), fetch(ctx))
+	if _, err := all.Timeout(5*time.Second, "rate limited fetch stuck").Await(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRateLimited_ContextCanceledReturnsToken(t *testing.T) {
+	limiter := NewTokenBucket(1, 1)
+	limiter.Allow()
+
+	fetch := RateLimited(limiter, func(ctx context.Context) *Promise[int] { return Resolve(1) })
+	ctx, cancel := context.WithCancel(context.Background())
+	p := fetch(ctx)
+	cancel()
+
+	var rlErr *RateLimitError
+	if _, err := p.Await(context.Background()); !errors.As(err, &rlErr) {
+		t.Fatalf("expected *RateLimitError, got %v", err)
+	}
+	// 预占的令牌已归还：桶中仍是取走唯一令牌后的状态
+	limiter.mu.Lock()
+	tokens := limiter.tokens
+	limiter.mu.Unlock()
+	if tokens < -0.01 {
+		t.Fatalf("reserved token not returned, tokens = %v", tokens)
+	}
+}
+
+// waitLimiter 只实现 Limiter (不实现 Reserver)，走派发任务中阻塞等待的路径
+type waitLimiter struct{ *TokenBucket }
+
+func (l waitLimiter) Wait(ctx context.Context) error { return l.TokenBucket.Wait(ctx) }
+
+func TestRateLimited_AlreadyCanceled(t *testing.T) {
+	limiters := map[string]Limiter{
+		"Reserver": NewTokenBucket(1000, 1),
+		"Limiter":  waitLimiter{NewTokenBucket(1000, 1)},
+	}
+	for name, limiter := range limiters {
+		t.Run(name, func(t *testing.T) {
+			var called int32
+			fetch := RateLimited(limiter, func(ctx context.Context) *Promise[int] {
+				atomic.AddInt32(&called, 1)
+				return Resolve(1)
+			})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			_, err := fetch(ctx).Await(context.Background())
+
+			var rlErr *RateLimitError
+			if !errors.As(err, &rlErr) {
+				t.Fatalf("expected *RateLimitError, got %T %v", err, err)
+			}
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("expected wrapped Canceled, got %v", rlErr.Err)
+			}
+			assertEqual(t, int32(0), atomic.LoadInt32(&called), "factory must not run")
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package promise
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// -------------------------------------------------------
+// Pending Promise 注册表与泄漏报告
+// -------------------------------------------------------
+
+// registryEnabled 注册表开关，见 TrackPending
+var registryEnabled atomic.Bool
+
+var (
+	// pendingRegistry id -> *pendingEntry，决议时移除
+	pendingRegistry sync.Map
+	// collectedPending 在 Pending 状态下被 GC 回收的 Promise 数量 (永远不会再被决议)
+	collectedPending atomic.Int64
+)
+
+// pendingEntry 注册表条目，对 Promise 只持有弱引用 (Go 1.24+)，不会阻止其被回收
+type pendingEntry struct {
+	obs   *observation
+	stack []uintptr
+	// inspect 返回当前挂载的回调数量；Promise 已被回收时 alive 为 false
+	inspect func() (handlers int, alive bool)
+}
+
+// TrackPending 开启/关闭 Pending Promise 注册表
+// 开启后新建的 Promise 在决议前都会登记创建调用栈，可通过 PendingPromises / DumpPending / Watchdog 查看；
+// 关闭时清空注册表。开启有额外开销 (runtime.Callers + map 操作)，适合排障时临时使用。
+func TrackPending(on bool) {
+	registryEnabled.Store(on)
+	if !on {
+		pendingRegistry.Range(func(key, _ interface{}) bool {
+			pendingRegistry.Delete(key)
+			return true
+		})
+	}
+}
+
+// register 登记新建且仍为 Pending 的 Promise
+func (p *Promise[T]) register() {
+	if p.obs == nil || !registryEnabled.Load() || p.GetState() != Pending {
+		return
+	}
+
+	var pcs [32]uintptr
+	n := runtime.Callers(3, pcs[:])
+	ref := makeRef(p)
+
+	pendingRegistry.Store(p.obs.id, &pendingEntry{
+		obs:   p.obs,
+		stack: append([]uintptr(nil), pcs[:n]...),
+		inspect: func() (int, bool) {
+			p := ref()
+			if p == nil {
+				return 0, false
+			}
+			return p.handlerCount(), true
+		},
+	})
+}
+
+// unregister 决议时移除登记
+func (p *Promise[T]) unregister() {
+	if p.obs != nil && registryEnabled.Load() {
+		pendingRegistry.Delete(p.obs.id)
+	}
+}
+
+// handlerCount 当前挂载 (尚未执行) 的回调数量
+// 遍历链表需要持有 p.mu 且节点执行后会被回收，因此由 pushHandler 在元数据上计数，读取时不加锁
+func (p *Promise[T]) handlerCount() int {
+	if p.obs == nil {
+		return 0
+	}
+	return int(p.obs.handlers.Load())
+}
+
+// PendingPromise 注册表中一个尚未决议的 Promise
+type PendingPromise struct {
+	Info
+	Age time.Duration
+	// Handlers 已挂载、等待决议的回调数量
+	Handlers int
+	// Stack 创建时的调用栈
+	Stack []runtime.Frame
+}
+
+// PendingPromises 返回注册表中所有尚未决议的 Promise，按存活时间从长到短排序
+// 已被 GC 回收的 Promise 会从注册表移除并计入 CollectedPending
+func PendingPromises() []PendingPromise {
+	now := time.Now()
+	var list []PendingPromise
+
+	pendingRegistry.Range(func(key, value interface{}) bool {
+		e := value.(*pendingEntry)
+		handlers, alive := e.inspect()
+		if !alive {
+			if _, loaded := pendingRegistry.LoadAndDelete(key); loaded {
+				collectedPending.Add(1)
+			}
+			return true
+		}
+
+		list = append(list, PendingPromise{
+			Info:     e.obs.info(Pending),
+			Age:      now.Sub(e.obs.created),
+			Handlers: handlers,
+			Stack:    symbolize(e.stack),
+		})
+		return true
+	})
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Age != list[j].Age {
+			return list[i].Age > list[j].Age
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list
+}
+
+// CollectedPending 返回在 Pending 状态下被 GC 回收的 Promise 数量 (需 Go 1.24+ 才能检测)
+// 这类 Promise 已不可能被决议，挂在其上的回调永远不会执行
+func CollectedPending() int64 {
+	return collectedPending.Load()
+}
+
+// DumpPending 以文本形式输出所有尚未决议的 Promise：名称、存活时间、回调数量和创建调用栈
+func DumpPending(w io.Writer) error {
+	list := PendingPromises()
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, "%d pending promise(s)\n", len(list))
+	if n := CollectedPending(); n > 0 {
+		fmt.Fprintf(bw, "%d promise(s) were garbage collected while still pending\n", n)
+	}
+	for _, pp := range list {
+		bw.WriteByte('\n')
+		label := pp.Op
+		if pp.Name != "" {
+			label = fmt.Sprintf("%q (%s)", pp.Name, pp.Op)
+		}
+		fmt.Fprintf(bw, "#%d %s age %v, %d handler(s)\n", pp.ID, label, pp.Age.Round(time.Millisecond), pp.Handlers)
+		for _, f := range pp.Stack {
+			fmt.Fprintf(bw, "\t%s\n\t\t%s:%d\n", f.Function, f.File, f.Line)
+		}
+	}
+	return bw.Flush()
+}
+
+// Watchdog 每隔 interval 检查一次注册表，把存活超过 olderThan 的 Promise 交给 fn
+// 每个 Promise 只会上报一次。返回的 stop 函数用于停止检查。需先调用 TrackPending(true)。
+func Watchdog(olderThan, interval time.Duration, fn func([]PendingPromise)) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	reported := make(map[uint64]struct{})
+
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+
+			var stale []PendingPromise
+			live := make(map[uint64]struct{}, len(reported))
+			for _, pp := range PendingPromises() {
+				if pp.Age < olderThan {
+					continue
+				}
+				live[pp.ID] = struct{}{}
+				if _, ok := reported[pp.ID]; !ok {
+					stale = append(stale, pp)
+				}
+			}
+			reported = live // 已决议的 Promise 不再记录
+			if len(stale) > 0 {
+				fn(stale)
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			ticker.Stop()
+			close(done)
+		})
+	}
+}
+
+func symbolize(pcs []uintptr) []runtime.Frame {
+	frames := runtime.CallersFrames(pcs)
+	var out []runtime.Frame
+	for {
+		f, more := frames.Next()
+		if !isLibraryFrame(f) {
+			out = append(out, f)
+		}
+		if !more {
+			return out
+		}
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !go1.24
+
+package promise
+
+// makeRef 旧版本 Go 没有 weak 包，退化为强引用：
+// 注册表会持有 Pending Promise 直到其决议或 TrackPending(false)
+func makeRef[T any](p *Promise[T]) func() *Promise[T] {
+	return func() *Promise[T] { return p }
+}
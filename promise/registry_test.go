@@ -0,0 +1,83 @@
+package promise
+
+import (
+	"bytes"
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRegistry_DumpPending(t *testing.T) {
+	setGlobal(t, TrackPending, registryEnabled.Load(), true)
+
+	resolveCh := make(chan func(int), 1)
+	stuck := New(func(resolve func(int), reject func(error)) {
+		resolveCh <- resolve
+	}, WithName("load-user"))
+	stuck.Then(func(v int) int { return v }, nil)
+	stuck.Then(func(v int) int { return v }, nil)
+	resolve := <-resolveCh
+
+	done := Resolve(1)
+	_, _ = done.Await(context.Background())
+
+	var buf bytes.Buffer
+	if err := DumpPending(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, `"load-user" (New) age `) || !strings.Contains(out, "2 handler(s)") {
+		t.Errorf("missing stuck promise in dump:\n%s", out)
+	}
+	if !strings.Contains(out, "TestRegistry_DumpPending") {
+		t.Errorf("dump should include creation stack:\n%s", out)
+	}
+
+	// 决议后从注册表移除 (两个 Then 子 Promise 也随之决议)
+	resolve(1)
+	_, _ = stuck.Await(context.Background())
+	time.Sleep(10 * time.Millisecond)
+	for _, pp := range PendingPromises() {
+		if pp.ID == stuck.ID() {
+			t.Errorf("settled promise still registered")
+		}
+	}
+}
+
+func TestRegistry_Watchdog(t *testing.T) {
+	setGlobal(t, TrackPending, registryEnabled.Load(), true)
+
+	never := New(func(resolve func(int), reject func(error)) {}, WithName("never"))
+	defer runtime.KeepAlive(never)
+
+	reports := make(chan []PendingPromise, 4)
+	stop := Watchdog(20*time.Millisecond, 5*time.Millisecond, func(list []PendingPromise) {
+		reports <- list
+	})
+	defer stop()
+
+	select {
+	case list := <-reports:
+		if len(list) != 1 || list[0].Name != "never" || list[0].Age < 20*time.Millisecond {
+			t.Errorf("unexpected watchdog report: %+v", list)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("watchdog did not report stale promise")
+	}
+
+	// 同一个 Promise 只上报一次
+	select {
+	case list := <-reports:
+		t.Errorf("duplicate report: %+v", list)
+	case <-time.After(30 * time.Millisecond):
+	}
+}
+
+func TestRegistry_Disabled(t *testing.T) {
+	New(func(resolve func(int), reject func(error)) {})
+	if n := len(PendingPromises()); n != 0 {
+		t.Errorf("registry should be empty when disabled, got %d", n)
+	}
+}
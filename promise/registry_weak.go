@@ -0,0 +1,11 @@
+//go:build go1.24
+
+package promise
+
+import "weak"
+
+// makeRef 返回对 p 的弱引用，注册表不会阻止 Promise 被回收
+func makeRef[T any](p *Promise[T]) func() *Promise[T] {
+	w := weak.Make(p)
+	return w.Value
+}
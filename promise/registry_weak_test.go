@@ -0,0 +1,40 @@
+//go:build go1.24
+
+package promise
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestRegistry_CollectedWhilePending(t *testing.T) {
+	setGlobal(t, TrackPending, registryEnabled.Load(), true)
+	before := CollectedPending()
+
+	// executor 丢弃了 resolve/reject，Promise 永远不会决议，且测试不再持有它
+	id := func() uint64 {
+		return New(func(resolve func(int), reject func(error)) {}).ID()
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		runtime.GC()
+		found := false
+		for _, pp := range PendingPromises() {
+			if pp.ID == id {
+				found = true
+			}
+		}
+		if !found {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("unreachable pending promise was not collected")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if CollectedPending() <= before {
+		t.Error("collected pending promise should be counted")
+	}
+}
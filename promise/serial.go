@@ -0,0 +1,102 @@
+package promise
+
+import (
+	"errors"
+	"sync"
+)
+
+// -------------------------------------------------------
+// 按键串行执行：同一个键的任务依次执行，不同键之间并行
+// -------------------------------------------------------
+
+// ErrSerialQueueFull 某个键排队的任务数已达到 SerialExecutor 的上限
+var ErrSerialQueueFull = errors.New("promise: serial executor queue full")
+
+// SerialExecutor 按键串行的执行器，例如同一账户的操作必须按提交顺序执行，不同账户之间互不阻塞
+// 每个键只记录最后提交的任务，新任务挂载在它之后，键上没有未完成的任务时立即清理，空闲的键不占用内存。
+//
+//	ex := promise.NewSerialExecutor[string](100)
+//	p := promise.Submit(ex, accountID, func(resolve func(int), reject func(error)) {
+//		resolve(debit(accountID, amount))
+//	})
+type SerialExecutor[K comparable] struct {
+	maxPending int
+
+	mu   sync.Mutex
+	keys map[K]*serialQueue
+}
+
+// serialQueue 单个键的队列状态，受 SerialExecutor.mu 保护
+type serialQueue struct {
+	pending int          // 已提交但尚未决议的任务数 (含正在执行的)
+	tail    func(func()) // 在最后提交的任务决议后执行给定函数
+}
+
+// NewSerialExecutor 创建执行器，maxPending 为每个键最多排队的任务数 (含正在执行的)，<= 0 表示不限制
+func NewSerialExecutor[K comparable](maxPending int) *SerialExecutor[K] {
+	return &SerialExecutor[K]{
+		maxPending: maxPending,
+		keys:       make(map[K]*serialQueue),
+	}
+}
+
+// Submit 提交 key 上的任务：前一个任务决议后才通过调度器执行 exec，返回的 Promise 在 exec 决议时决议
+// 前一个任务失败不影响后续任务。exec 必须最终调用 resolve 或 reject，否则该键上之后的任务永远不会执行。
+// 该键排队的任务数已达上限时返回以 ErrSerialQueueFull 拒绝的 Promise。
+func Submit[T any, K comparable](ex *SerialExecutor[K], key K, exec func(resolve func(T), reject func(error))) *Promise[T] {
+	ex.mu.Lock()
+	q := ex.keys[key]
+	if q == nil {
+		q = &serialQueue{}
+		ex.keys[key] = q
+	}
+	if ex.maxPending > 0 && q.pending >= ex.maxPending {
+		ex.mu.Unlock()
+		return Reject[T](ErrSerialQueueFull)
+	}
+
+	p := &Promise[T]{}
+	p.observe(nil, "Submit", nil)
+
+	q.pending++
+	prev := q.tail
+	q.tail = func(next func()) { attachHandler(p, next) }
+	ex.mu.Unlock()
+
+	// 先挂载清理回调，再启动任务，保证 p 决议时计数一定会被扣减
+	attachHandler(p, func() { ex.done(key, q) })
+	start := func() { p.run("Submit", exec) }
+	if prev == nil {
+		start()
+	} else {
+		prev(start)
+	}
+	return p
+}
+
+// Pending 返回 key 上已提交但尚未决议的任务数
+func (ex *SerialExecutor[K]) Pending(key K) int {
+	ex.mu.Lock()
+	defer ex.mu.Unlock()
+	if q := ex.keys[key]; q != nil {
+		return q.pending
+	}
+	return 0
+}
+
+// Len 返回当前有未完成任务的键的数量
+func (ex *SerialExecutor[K]) Len() int {
+	ex.mu.Lock()
+	defer ex.mu.Unlock()
+	return len(ex.keys)
+}
+
+// done 任务决议后扣减计数，键上没有未完成的任务时清理
+func (ex *SerialExecutor[K]) done(key K, q *serialQueue) {
+	ex.mu.Lock()
+	q.pending--
+	if q.pending == 0 {
+		delete(ex.keys, key)
+	}
+	ex.mu.Unlock()
+}
@@ -0,0 +1,103 @@
+package promise
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSerialExecutor_OrderPerKey(t *testing.T) {
+	ex := NewSerialExecutor[string](0)
+
+	var mu sync.Mutex
+	order := map[string][]int{}
+	running := map[string]bool{}
+
+	var all []*Promise[int]
+	for i := 0; i < 20; i++ {
+		for _, key := range []string{"a", "b"} {
+			i, key := i, key
+			all = append(all, Submit(ex, key, func(resolve func(int), reject func(error)) {
+				mu.Lock()
+				if running[key] {
+					mu.Unlock()
+					reject(errors.New("overlapping tasks on " + key))
+					return
+				}
+				running[key] = true
+				mu.Unlock()
+
+				// 异步决议：下一个任务必须等到 resolve 之后才开始
+				go func() {
+					time.Sleep(100 * time.Microsecond)
+					mu.Lock()
+					running[key] = false
+					order[key] = append(order[key], i)
+					mu.Unlock()
+					resolve(i)
+				}()
+			}))
+		}
+	}
+
+	if _, err := All(all...).Await(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for key, got := range order {
+		for i, v := range got {
+			if v != i {
+				t.Fatalf("key %s: order = %v, want submission order", key, got)
+			}
+		}
+	}
+}
+
+func TestSerialExecutor_FailureDoesNotBlock(t *testing.T) {
+	ex := NewSerialExecutor[int](0)
+
+	first := Submit(ex, 1, func(resolve func(int), reject func(error)) { panic("boom") })
+	second := Submit(ex, 1, func(resolve func(int), reject func(error)) { resolve(2) })
+
+	if _, err := first.Await(context.Background()); err == nil {
+		t.Fatal("expected first task to reject")
+	}
+	if v, err := second.Await(context.Background()); err != nil || v != 2 {
+		t.Fatalf("second = %v, %v", v, err)
+	}
+}
+
+func TestSerialExecutor_LimitAndCleanup(t *testing.T) {
+	ex := NewSerialExecutor[string](2)
+
+	release := make(chan struct{})
+	blocked := func(resolve func(int), reject func(error)) {
+		go func() {
+			<-release
+			resolve(1)
+		}()
+	}
+	p1 := Submit(ex, "acct", blocked)
+	p2 := Submit(ex, "acct", blocked)
+	if _, err := Submit(ex, "acct", blocked).Await(context.Background()); !errors.Is(err, ErrSerialQueueFull) {
+		t.Fatalf("expected ErrSerialQueueFull, got %v", err)
+	}
+	if n := ex.Pending("acct"); n != 2 {
+		t.Fatalf("Pending = %d, want 2", n)
+	}
+
+	close(release)
+	if _, err := All(p1, p2).Await(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// 清理回调在决议方执行，可能晚于 Await 返回
+	deadline := time.Now().Add(time.Second)
+	for ex.Len() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("idle key not cleaned up, Len = %d", ex.Len())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,112 @@
+package promise
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// -------------------------------------------------------
+// log/slog 集成
+// -------------------------------------------------------
+
+// SlogOption SlogObserver 配置项
+type SlogOption func(*slogObserver)
+
+// WithSlowThreshold 记录从创建到决议耗时不少于 d 的 Promise (无论成功与否)，d <= 0 表示不记录 (默认)
+func WithSlowThreshold(d time.Duration) SlogOption {
+	return func(o *slogObserver) {
+		o.slow = d
+	}
+}
+
+// SlogObserver 返回把 Promise 生命周期事件写入 logger 的 Observer，配合 AddObserver 使用：
+//
+//	remove := promise.AddObserver(promise.SlogObserver(slog.Default(), slog.LevelWarn,
+//		promise.WithSlowThreshold(time.Second)))
+//	defer remove()
+//
+// 记录的事件：
+//   - 拒绝 ("promise rejected")：只记录产生错误的 Promise，沿链路传播的拒绝不会重复记录
+//   - 超时 ("promise timed out")：Timeout 超时或 context 截止时间到达
+//   - 慢决议 ("promise settled slowly")：见 WithSlowThreshold
+//   - Panic ("promise panicked")：级别不低于 slog.LevelError，附带 Panic 现场调用栈
+//
+// 以上事件均以 level 记录 (Panic 除外)，属性位于 "promise" 分组下：id、name、op、state、duration、error、site。
+func SlogObserver(logger *slog.Logger, level slog.Level, opts ...SlogOption) Observer {
+	o := &slogObserver{logger: logger, level: level}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+type slogObserver struct {
+	logger *slog.Logger
+	level  slog.Level
+	slow   time.Duration
+}
+
+func (o *slogObserver) OnSettled(ev SettleInfo) {
+	var msg string
+	switch {
+	case ev.State == Rejected && !ev.Propagated && isTimeout(ev.Err):
+		msg = "promise timed out"
+	case ev.State == Rejected && !ev.Propagated:
+		msg = "promise rejected"
+	case o.slow > 0 && ev.Duration >= o.slow:
+		msg = "promise settled slowly"
+	default:
+		return
+	}
+
+	ctx := context.Background()
+	if !o.logger.Enabled(ctx, o.level) {
+		return
+	}
+	attrs := promiseAttrs(ev.Info)
+	attrs = append(attrs, slog.Duration("duration", ev.Duration))
+	if ev.Err != nil {
+		attrs = append(attrs, slog.Any("error", ev.Err))
+	}
+	o.logger.LogAttrs(ctx, o.level, msg, slog.Attr{Key: "promise", Value: slog.GroupValue(attrs...)})
+}
+
+func (o *slogObserver) OnPanic(ev PanicInfo) {
+	level := o.level
+	if level < slog.LevelError {
+		level = slog.LevelError
+	}
+
+	ctx := context.Background()
+	if !o.logger.Enabled(ctx, level) {
+		return
+	}
+	attrs := promiseAttrs(ev.Info)
+	attrs = append(attrs, slog.Any("panic", ev.Value), slog.String("stack", string(ev.Stack)))
+	o.logger.LogAttrs(ctx, level, "promise panicked", slog.Attr{Key: "promise", Value: slog.GroupValue(attrs...)})
+}
+
+// promiseAttrs Promise 的基本属性，未设置的字段省略
+func promiseAttrs(info Info) []slog.Attr {
+	attrs := make([]slog.Attr, 0, 8)
+	if info.ID != 0 {
+		attrs = append(attrs, slog.Uint64("id", info.ID))
+	}
+	if info.Name != "" {
+		attrs = append(attrs, slog.String("name", info.Name))
+	}
+	if info.Op != "" {
+		attrs = append(attrs, slog.String("op", info.Op))
+	}
+	attrs = append(attrs, slog.String("state", info.State.String()))
+	if info.Site.File != "" {
+		attrs = append(attrs, slog.String("site", info.Site.String()))
+	}
+	return attrs
+}
+
+func isTimeout(err error) bool {
+	return errors.Is(err, ErrTimeout) || errors.Is(err, context.DeadlineExceeded)
+}
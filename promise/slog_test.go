@@ -0,0 +1,169 @@
+package promise
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// logBuffer 并发安全的日志缓冲，按行解析 JSON 记录
+type logBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *logBuffer) records(t *testing.T) []map[string]interface{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var out []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(b.buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var rec map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("invalid log line %q: %v", line, err)
+		}
+		out = append(out, rec)
+	}
+	return out
+}
+
+func withSlog(t *testing.T, handlerLevel, level slog.Level, opts ...SlogOption) *logBuffer {
+	buf := &logBuffer{}
+	logger := slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: handlerLevel}))
+	remove := AddObserver(SlogObserver(logger, level, opts...))
+	t.Cleanup(remove)
+	return buf
+}
+
+func promiseGroup(t *testing.T, rec map[string]interface{}) map[string]interface{} {
+	g, ok := rec["promise"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("record has no promise group: %v", rec)
+	}
+	return g
+}
+
+func TestSlogObserver_RejectionLoggedOnce(t *testing.T) {
+	buf := withSlog(t, slog.LevelInfo, slog.LevelWarn)
+
+	p := New(func(resolve func(int), reject func(error)) {
+		reject(errors.New("boom"))
+	}, WithName("load-user"))
+	tail := p.Then(func(v int) int { return v + 1 }, nil).
+		Finally(func() {})
+	if _, err := tail.Await(context.Background()); err == nil {
+		t.Fatal("expected rejection")
+	}
+
+	recs := buf.records(t)
+	if len(recs) != 1 {
+		t.Fatalf("propagated rejections should not be logged again, got %d records: %v", len(recs), recs)
+	}
+	rec := recs[0]
+	if rec["msg"] != "promise rejected" || rec["level"] != "WARN" {
+		t.Errorf("unexpected record %v", rec)
+	}
+	g := promiseGroup(t, rec)
+	if g["name"] != "load-user" || g["op"] != "New" || g["state"] != "rejected" {
+		t.Errorf("unexpected attrs %v", g)
+	}
+	// 调试模式下错误文本附带来源 Promise 的创建位置
+	if msg, _ := g["error"].(string); !strings.HasPrefix(msg, "boom") {
+		t.Errorf("unexpected attrs %v", g)
+	}
+	if _, ok := g["duration"]; !ok {
+		t.Error("missing duration")
+	}
+}
+
+func TestSlogObserver_Timeout(t *testing.T) {
+	buf := withSlog(t, slog.LevelInfo, slog.LevelWarn)
+
+	never := New(func(resolve func(int), reject func(error)) {})
+	_, err := never.Timeout(10*time.Millisecond, "").Await(context.Background())
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("expected ErrTimeout, got %v", err)
+	}
+
+	recs := buf.records(t)
+	if len(recs) != 1 || recs[0]["msg"] != "promise timed out" {
+		t.Fatalf("expected one timeout record, got %v", recs)
+	}
+	if g := promiseGroup(t, recs[0]); g["op"] != "Timeout" {
+		t.Errorf("unexpected attrs %v", g)
+	}
+}
+
+func TestSlogObserver_SlowSettlement(t *testing.T) {
+	buf := withSlog(t, slog.LevelInfo, slog.LevelInfo, WithSlowThreshold(20*time.Millisecond))
+
+	if _, err := Resolve(1).Await(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Delay(30 * time.Millisecond).Await(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	recs := buf.records(t)
+	if len(recs) != 1 || recs[0]["msg"] != "promise settled slowly" {
+		t.Fatalf("expected one slow record, got %v", recs)
+	}
+	if g := promiseGroup(t, recs[0]); g["op"] != "Delay" || g["state"] != "fulfilled" {
+		t.Errorf("unexpected attrs %v", g)
+	}
+}
+
+func TestSlogObserver_PanicAtErrorLevel(t *testing.T) {
+	// 拒绝以 Debug 记录会被过滤，Panic 仍以 Error 记录
+	buf := withSlog(t, slog.LevelInfo, slog.LevelDebug)
+
+	p := New(func(resolve func(int), reject func(error)) {
+		panic("kaboom")
+	})
+	if _, err := p.Await(context.Background()); err == nil {
+		t.Fatal("expected rejection")
+	}
+
+	recs := buf.records(t)
+	if len(recs) != 1 {
+		t.Fatalf("expected only the panic record, got %v", recs)
+	}
+	rec := recs[0]
+	if rec["msg"] != "promise panicked" || rec["level"] != "ERROR" {
+		t.Errorf("unexpected record %v", rec)
+	}
+	g := promiseGroup(t, rec)
+	if g["panic"] != "kaboom" || !strings.Contains(g["stack"].(string), "slog_test.go") {
+		t.Errorf("unexpected attrs %v", g)
+	}
+}
+
+func TestAddObserver_Remove(t *testing.T) {
+	buf := &logBuffer{}
+	remove := AddObserver(SlogObserver(slog.New(slog.NewJSONHandler(buf, nil)), slog.LevelWarn))
+	remove()
+	remove()
+
+	if loadObservers() != nil {
+		t.Fatal("observer list should be empty after remove")
+	}
+	_, _ = Reject[int](errors.New("x")).Await(context.Background())
+	if recs := buf.records(t); len(recs) != 0 {
+		t.Errorf("removed observer still logging: %v", recs)
+	}
+}
@@ -0,0 +1,90 @@
+package promise
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// Tracer 追踪钩子接口
+// 通过 SetTracer 设置后，每个 Promise (包括 Then / Finally / Map / 聚合产生的子 Promise) 都会上报
+// 创建、派发、决议和回调执行事件。未设置 Tracer 时钩子开销仅为一次原子读取。
+//
+// 调用约定 (同步调用、须并发安全) 见包文档的「钩子」一节。
+type Tracer interface {
+	OnCreate(ev CreateEvent)
+	OnDispatch(ev DispatchEvent)
+	OnSettle(ev SettleEvent)
+	OnHandlerRun(ev HandlerEvent)
+}
+
+// CreateEvent Promise 创建事件
+type CreateEvent struct {
+	// Ctx 创建时的上下文，仅 NewWithContext 等带 ctx 的入口会设置，否则为 nil
+	Ctx context.Context
+	// ID Promise 的唯一标识 (仅在追踪开启时分配)
+	ID uint64
+	// Op 创建操作，如 "New"、"Then"、"Map"、"All"
+	Op string
+	// Name 通过 WithName 设置的名称，未设置时为空
+	Name string
+	// Parents 上游 Promise 的 ID (Then/Map 为 1 个，聚合为多个，根 Promise 为空)
+	Parents []uint64
+	Time    time.Time
+}
+
+// DispatchEvent 任务被提交到 GlobalDispatcher 的事件
+type DispatchEvent struct {
+	ID   uint64
+	Time time.Time
+}
+
+// SettleEvent Promise 决议事件
+type SettleEvent struct {
+	ID    uint64
+	State State
+	Err   error
+	Time  time.Time
+	// Propagated 拒绝原因来自上游 Promise (沿 Then / Finally / 聚合等链路传播)，而非在此处产生
+	Propagated bool
+}
+
+// HandlerEvent 用户回调 (executor / Then / Finally 回调) 执行完毕事件
+type HandlerEvent struct {
+	// ID 回调所属 (即回调结果将决议的) Promise
+	ID       uint64
+	Op       string
+	Start    time.Time
+	Duration time.Duration
+}
+
+// NopTracer 空实现，可嵌入自定义 Tracer 以只实现部分钩子
+type NopTracer struct{}
+
+func (NopTracer) OnCreate(CreateEvent)      {}
+func (NopTracer) OnDispatch(DispatchEvent)  {}
+func (NopTracer) OnSettle(SettleEvent)      {}
+func (NopTracer) OnHandlerRun(HandlerEvent) {}
+
+type tracerBox struct {
+	t Tracer
+}
+
+var globalTracer atomic.Pointer[tracerBox]
+
+// SetTracer 设置全局 Tracer，传入 nil 关闭追踪
+// 只影响之后创建的 Promise
+func SetTracer(t Tracer) {
+	if t == nil {
+		globalTracer.Store(nil)
+		return
+	}
+	globalTracer.Store(&tracerBox{t: t})
+}
+
+func loadTracer() Tracer {
+	if b := globalTracer.Load(); b != nil {
+		return b.t
+	}
+	return nil
+}
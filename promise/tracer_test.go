@@ -0,0 +1,93 @@
+package promise
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+// recordingTracer 记录所有追踪事件
+type recordingTracer struct {
+	mu       sync.Mutex
+	created  map[uint64]CreateEvent
+	settled  map[uint64]SettleEvent
+	dispatch map[uint64]int
+	handlers map[uint64]int
+}
+
+func newRecordingTracer() *recordingTracer {
+	return &recordingTracer{
+		created:  make(map[uint64]CreateEvent),
+		settled:  make(map[uint64]SettleEvent),
+		dispatch: make(map[uint64]int),
+		handlers: make(map[uint64]int),
+	}
+}
+
+func (r *recordingTracer) OnCreate(ev CreateEvent) {
+	r.mu.Lock()
+	r.created[ev.ID] = ev
+	r.mu.Unlock()
+}
+
+func (r *recordingTracer) OnDispatch(ev DispatchEvent) {
+	r.mu.Lock()
+	r.dispatch[ev.ID]++
+	r.mu.Unlock()
+}
+
+func (r *recordingTracer) OnSettle(ev SettleEvent) {
+	r.mu.Lock()
+	r.settled[ev.ID] = ev
+	r.mu.Unlock()
+}
+
+func (r *recordingTracer) OnHandlerRun(ev HandlerEvent) {
+	r.mu.Lock()
+	r.handlers[ev.ID]++
+	r.mu.Unlock()
+}
+
+func TestTracer_ParentLinks(t *testing.T) {
+	tr := newRecordingTracer()
+	setGlobal[Tracer](t, SetTracer, loadTracer(), tr)
+
+	root := New(func(resolve func(int), reject func(error)) { resolve(1) })
+	then := root.Then(func(v int) int { return v + 1 }, nil)
+	mapped := Map(then, func(v int) (string, error) { return "x", nil })
+	other := Reject[string](errors.New("fail"))
+	all := AllSettled(mapped, other)
+
+	if _, err := all.Await(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	assertEqual(t, "New", tr.created[root.ID()].Op, "root op")
+	assertEqual(t, root.ID(), tr.created[then.ID()].Parents[0], "then parent")
+	assertEqual(t, then.ID(), tr.created[mapped.ID()].Parents[0], "map parent")
+
+	joined := tr.created[all.ID()]
+	assertEqual(t, "AllSettled", joined.Op, "aggregate op")
+	if len(joined.Parents) != 2 || joined.Parents[0] != mapped.ID() || joined.Parents[1] != other.ID() {
+		t.Errorf("aggregate parents: got %v", joined.Parents)
+	}
+
+	assertEqual(t, Fulfilled, tr.settled[then.ID()].State, "then settle")
+	assertEqual(t, Rejected, tr.settled[other.ID()].State, "reject settle")
+	assertEqual(t, 1, tr.dispatch[root.ID()], "root executor dispatched")
+	assertEqual(t, 1, tr.handlers[then.ID()], "then handler run")
+}
+
+func TestTracer_Disabled(t *testing.T) {
+	p := Resolve(1).Then(func(v int) int { return v }, nil)
+	_, _ = p.Await(context.Background())
+	if DebugEnabled() {
+		// 调试模式总会分配 ID
+		return
+	}
+	assertEqual(t, uint64(0), p.ID(), "no id without tracer")
+}
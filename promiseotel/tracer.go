@@ -0,0 +1,228 @@
+// Package promiseotel 将 go-promise 的 Tracer 钩子转换为 OpenTelemetry Span。
+//
+// 每个 Promise 对应一个名为 "promise.<Op>" 的 Span：创建时开始、决议时结束，
+// 携带 promise.id / promise.op / promise.name / promise.state / promise.duration_ms 属性，拒绝时记录错误并设置 Error 状态。
+// 派发与回调执行以 Span Event 的形式记录。Then / Map 的 Span 以上游 Promise 的 Span 为父 Span，
+// 聚合操作 (All / Any / Race / AllSettled) 则对所有上游 Span 添加 Link。
+// 永不决议的 Promise 不会结束 Span，未结束的 Span 数量受 WithMaxLive 限制。
+//
+//	promise.SetTracer(promiseotel.New(otel.GetTracerProvider()))
+package promiseotel
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/xigexb/go-promise/promise"
+)
+
+// instrumentationName OpenTelemetry 的 instrumentation scope 名称
+const instrumentationName = "github.com/xigexb/go-promise/promiseotel"
+
+// DefaultRetainEnded 默认保留的已结束 Span 上下文数量，用于为之后创建的子 Promise 建立父子关系
+const DefaultRetainEnded = 4096
+
+// DefaultMaxLive 默认最多同时跟踪的未结束 Span 数量
+const DefaultMaxLive = 65536
+
+// Tracer 实现 promise.Tracer
+type Tracer struct {
+	tracer trace.Tracer
+
+	mu       sync.Mutex
+	live     map[uint64]*liveSpan
+	liveList list.List // 未结束的 Span，按创建顺序排列 (元素为 *liveSpan)
+	ended    map[uint64]trace.SpanContext
+	order    []uint64 // ended 的淘汰顺序 (FIFO)
+	retain   int
+	maxLive  int
+}
+
+type liveSpan struct {
+	id    uint64
+	span  trace.Span
+	start time.Time
+	elem  *list.Element
+}
+
+// Option 配置项
+type Option func(*Tracer)
+
+// WithRetainEnded 设置保留的已结束 Span 上下文数量
+// 在已决议的 Promise 上调用 Then 时，需要它来找到父 Span
+func WithRetainEnded(n int) Option {
+	return func(t *Tracer) {
+		t.retain = n
+	}
+}
+
+// WithMaxLive 设置最多同时跟踪的未结束 Span 数量，n <= 0 表示不限制
+// 永不决议或被丢弃的 Promise 不会触发 OnSettle，超过上限时最早开始的 Span
+// 以 promise.state=abandoned 结束，避免这类 Span 无限堆积。
+func WithMaxLive(n int) Option {
+	return func(t *Tracer) {
+		t.maxLive = n
+	}
+}
+
+// New 基于 TracerProvider 创建适配器
+func New(tp trace.TracerProvider, opts ...Option) *Tracer {
+	t := &Tracer{
+		tracer:  tp.Tracer(instrumentationName),
+		live:    make(map[uint64]*liveSpan),
+		ended:   make(map[uint64]trace.SpanContext),
+		retain:  DefaultRetainEnded,
+		maxLive: DefaultMaxLive,
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// spanContext 查找 Promise 对应的 Span 上下文 (调用方需持有锁)
+func (t *Tracer) spanContext(id uint64) (trace.SpanContext, bool) {
+	if s, ok := t.live[id]; ok {
+		return s.span.SpanContext(), true
+	}
+	sc, ok := t.ended[id]
+	return sc, ok
+}
+
+// OnCreate 开始 Span
+func (t *Tracer) OnCreate(ev promise.CreateEvent) {
+	ctx := ev.Ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	t.mu.Lock()
+	var links []trace.Link
+	switch len(ev.Parents) {
+	case 0:
+	case 1:
+		if sc, ok := t.spanContext(ev.Parents[0]); ok {
+			ctx = trace.ContextWithSpanContext(ctx, sc)
+		}
+	default:
+		for _, parent := range ev.Parents {
+			if sc, ok := t.spanContext(parent); ok {
+				links = append(links, trace.Link{SpanContext: sc})
+			}
+		}
+	}
+	t.mu.Unlock()
+
+	attrs := []attribute.KeyValue{
+		attribute.Int64("promise.id", int64(ev.ID)),
+		attribute.String("promise.op", ev.Op),
+	}
+	if ev.Name != "" {
+		attrs = append(attrs, attribute.String("promise.name", ev.Name))
+	}
+	_, span := t.tracer.Start(ctx, "promise."+ev.Op,
+		trace.WithTimestamp(ev.Time),
+		trace.WithLinks(links...),
+		trace.WithAttributes(attrs...),
+	)
+
+	s := &liveSpan{id: ev.ID, span: span, start: ev.Time}
+	var evicted *liveSpan
+	t.mu.Lock()
+	s.elem = t.liveList.PushBack(s)
+	t.live[ev.ID] = s
+	if t.maxLive > 0 && len(t.live) > t.maxLive {
+		evicted = t.liveList.Front().Value.(*liveSpan)
+		t.removeLive(evicted)
+	}
+	t.mu.Unlock()
+
+	if evicted != nil {
+		evicted.span.SetAttributes(attribute.String("promise.state", "abandoned"))
+		evicted.span.End()
+	}
+}
+
+// removeLive 将 Span 从未结束集合移入已结束集合 (调用方需持有锁)
+func (t *Tracer) removeLive(s *liveSpan) {
+	delete(t.live, s.id)
+	t.liveList.Remove(s.elem)
+	t.retainEnded(s.id, s.span.SpanContext())
+}
+
+// OnDispatch 记录派发事件
+func (t *Tracer) OnDispatch(ev promise.DispatchEvent) {
+	t.mu.Lock()
+	s, ok := t.live[ev.ID]
+	t.mu.Unlock()
+
+	if ok {
+		s.span.AddEvent("dispatch", trace.WithTimestamp(ev.Time))
+	}
+}
+
+// OnHandlerRun 记录回调执行事件
+// executor 可能在决议后才返回，此时 Span 已结束，事件被忽略
+func (t *Tracer) OnHandlerRun(ev promise.HandlerEvent) {
+	t.mu.Lock()
+	s, ok := t.live[ev.ID]
+	t.mu.Unlock()
+
+	if ok {
+		s.span.AddEvent("handler", trace.WithTimestamp(ev.Start), trace.WithAttributes(
+			attribute.String("promise.handler.op", ev.Op),
+			attribute.Float64("promise.handler.duration_ms", durationMillis(ev.Duration)),
+		))
+	}
+}
+
+// OnSettle 结束 Span
+func (t *Tracer) OnSettle(ev promise.SettleEvent) {
+	t.mu.Lock()
+	s, ok := t.live[ev.ID]
+	if ok {
+		t.removeLive(s)
+	}
+	t.mu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	s.span.SetAttributes(
+		attribute.String("promise.state", ev.State.String()),
+		attribute.Float64("promise.duration_ms", durationMillis(ev.Time.Sub(s.start))),
+	)
+	if ev.State == promise.Rejected {
+		if ev.Err != nil {
+			s.span.RecordError(ev.Err, trace.WithTimestamp(ev.Time))
+			s.span.SetStatus(codes.Error, ev.Err.Error())
+		} else {
+			s.span.SetStatus(codes.Error, "rejected")
+		}
+	}
+	s.span.End(trace.WithTimestamp(ev.Time))
+}
+
+// retainEnded 记录已结束 Span 的上下文，超过上限时淘汰最早的 (调用方需持有锁)
+func (t *Tracer) retainEnded(id uint64, sc trace.SpanContext) {
+	if t.retain <= 0 {
+		return
+	}
+	t.ended[id] = sc
+	t.order = append(t.order, id)
+	if len(t.order) > t.retain {
+		delete(t.ended, t.order[0])
+		t.order = t.order[1:]
+	}
+}
+
+func durationMillis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
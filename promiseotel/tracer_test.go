@@ -0,0 +1,118 @@
+package promiseotel
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/xigexb/go-promise/promise"
+)
+
+func setup(t *testing.T, opts ...Option) *tracetest.InMemoryExporter {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	promise.SetTracer(New(tp, opts...))
+	t.Cleanup(func() {
+		promise.SetTracer(nil)
+		_ = tp.Shutdown(context.Background())
+	})
+	return exporter
+}
+
+func spanByName(t *testing.T, spans tracetest.SpanStubs, name string) tracetest.SpanStub {
+	t.Helper()
+	for _, s := range spans {
+		if s.Name == name {
+			return s
+		}
+	}
+	t.Fatalf("span %q not found in %d spans", name, len(spans))
+	return tracetest.SpanStub{}
+}
+
+func TestTracer_ChainSpans(t *testing.T) {
+	exporter := setup(t)
+
+	root := promise.New(func(resolve func(int), reject func(error)) { resolve(1) })
+	child := root.Then(func(v int) int { return v * 2 }, nil)
+	if _, err := child.Await(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	spans := exporter.GetSpans()
+	rootSpan := spanByName(t, spans, "promise.New")
+	thenSpan := spanByName(t, spans, "promise.Then")
+
+	if thenSpan.Parent.SpanID() != rootSpan.SpanContext.SpanID() {
+		t.Errorf("Then span should be child of New span")
+	}
+	if thenSpan.SpanContext.TraceID() != rootSpan.SpanContext.TraceID() {
+		t.Errorf("chain should share a trace id")
+	}
+
+	attrs := map[string]string{}
+	for _, kv := range thenSpan.Attributes {
+		attrs[string(kv.Key)] = kv.Value.Emit()
+	}
+	if attrs["promise.state"] != "fulfilled" {
+		t.Errorf("expected state attribute, got %v", attrs)
+	}
+	if _, ok := attrs["promise.duration_ms"]; !ok {
+		t.Errorf("expected duration attribute, got %v", attrs)
+	}
+}
+
+func TestTracer_RejectionAndLinks(t *testing.T) {
+	exporter := setup(t)
+
+	fail := errors.New("boom")
+	a := promise.Resolve(1)
+	b := promise.Reject[int](fail)
+	if _, err := promise.All(a, b).Await(context.Background()); err != fail {
+		t.Fatalf("expected rejection, got %v", err)
+	}
+
+	spans := exporter.GetSpans()
+	rejectSpan := spanByName(t, spans, "promise.Reject")
+	if rejectSpan.Status.Code != codes.Error || rejectSpan.Status.Description != "boom" {
+		t.Errorf("expected error status, got %+v", rejectSpan.Status)
+	}
+	if len(rejectSpan.Events) == 0 || rejectSpan.Events[0].Name != "exception" {
+		t.Errorf("expected recorded error event, got %+v", rejectSpan.Events)
+	}
+
+	allSpan := spanByName(t, spans, "promise.All")
+	if len(allSpan.Links) != 2 {
+		t.Errorf("aggregate span should link both inputs, got %d links", len(allSpan.Links))
+	}
+}
+
+func TestTracer_MaxLiveEndsAbandonedSpans(t *testing.T) {
+	exporter := setup(t, WithMaxLive(2))
+
+	never := func(name string) *promise.Promise[int] {
+		return promise.New(func(resolve func(int), reject func(error)) {}, promise.WithName(name))
+	}
+	never("first")
+	never("second")
+	if n := len(exporter.GetSpans()); n != 0 {
+		t.Fatalf("expected no ended spans below the cap, got %d", n)
+	}
+	never("third")
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("expected the oldest span to be ended, got %d spans", len(spans))
+	}
+	attrs := map[string]string{}
+	for _, kv := range spans[0].Attributes {
+		attrs[string(kv.Key)] = kv.Value.Emit()
+	}
+	if attrs["promise.name"] != "first" || attrs["promise.state"] != "abandoned" {
+		t.Errorf("unexpected evicted span attributes %v", attrs)
+	}
+}
@@ -0,0 +1,227 @@
+// Package promisevet 提供检查 go-promise 常见误用的 go/analysis 分析器：
+//
+//   - 返回 *promise.Promise[T] (或 *promise.Pooled[T]) 的调用结果被直接丢弃 (既没有 Await 也没有挂载 Catch)
+//   - 作为语句结尾的链式调用没有处理拒绝 (最后一环不是 Catch、带 onRejected 的 Then / ThenSync 或 Tap)
+//   - 在带有 context.Context 参数的函数中调用 Await(context.Background()) / Await(context.TODO()) 或不带 ctx 的 Wait()
+//
+// 显式赋值给空白标识符 (_ = p.Then(...)) 视为有意忽略，不会报告。
+// 命令行工具见 cmd/promisevet，也可以通过 multichecker / gopls 集成 Analyzer。
+package promisevet
+
+import (
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// promisePkg go-promise 核心包的导入路径
+const promisePkg = "github.com/xigexb/go-promise/promise"
+
+const doc = `report unawaited and unhandled promises
+
+Reports calls returning *promise.Promise[T] whose result is discarded,
+promise chains used as statements that end without a rejection handler,
+and Await(context.Background()) or Wait() inside functions that receive a ctx.`
+
+// Analyzer 检查未 Await / 未处理拒绝的 Promise
+var Analyzer = &analysis.Analyzer{
+	Name:     "promisevet",
+	Doc:      doc,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	ins := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	filter := []ast.Node{(*ast.ExprStmt)(nil), (*ast.CallExpr)(nil)}
+	ins.WithStack(filter, func(n ast.Node, push bool, stack []ast.Node) bool {
+		if !push {
+			return true
+		}
+		switch n := n.(type) {
+		case *ast.ExprStmt:
+			checkDiscarded(pass, n)
+		case *ast.CallExpr:
+			checkAwaitContext(pass, n, stack)
+		}
+		return true
+	})
+	return nil, nil
+}
+
+// checkDiscarded 检查作为语句的 Promise 调用
+func checkDiscarded(pass *analysis.Pass, stmt *ast.ExprStmt) {
+	call, ok := ast.Unparen(stmt.X).(*ast.CallExpr)
+	if !ok || !isPromise(pass.TypesInfo.TypeOf(call)) {
+		return
+	}
+
+	method, recv := promiseMethod(pass, call)
+	if recv == nil {
+		pass.ReportRangef(call, "result of %s is discarded: Await it or attach a Catch", callName(call))
+		return
+	}
+	if !handlesRejection(pass, method, call) {
+		pass.ReportRangef(call, "promise chain ends with %s without a rejection handler: add Catch or Await the result", method)
+	}
+}
+
+// handlesRejection 链式调用的最后一环是否处理了拒绝
+func handlesRejection(pass *analysis.Pass, method string, call *ast.CallExpr) bool {
+	switch method {
+	case "Catch", "Tap":
+		return true
+	case "Then", "ThenSync":
+		return len(call.Args) == 2 && !isNil(pass, call.Args[1])
+	default:
+		return false
+	}
+}
+
+// checkAwaitContext 检查在带 ctx 参数的函数中 Await(context.Background()) 或 Wait()
+func checkAwaitContext(pass *analysis.Pass, call *ast.CallExpr, stack []ast.Node) {
+	method, recv := promiseMethod(pass, call)
+	if recv == nil {
+		return
+	}
+	if method == "Wait" && len(call.Args) == 0 {
+		if name := enclosingContextParam(pass, stack); name != "" {
+			pass.ReportRangef(call, "Wait() ignores cancellation: use Await(%s) instead", name)
+		}
+		return
+	}
+	if method != "Await" || len(call.Args) != 1 {
+		return
+	}
+	arg, ok := ast.Unparen(call.Args[0]).(*ast.CallExpr)
+	if !ok {
+		return
+	}
+	fn := calledFunc(pass, arg)
+	if fn == nil || fn.Pkg() == nil || fn.Pkg().Path() != "context" || (fn.Name() != "Background" && fn.Name() != "TODO") {
+		return
+	}
+
+	name := enclosingContextParam(pass, stack)
+	if name == "" {
+		return
+	}
+	pass.ReportRangef(call, "Await(context.%s()) ignores cancellation: pass %s instead", fn.Name(), name)
+}
+
+// enclosingContextParam 由内向外查找所在函数的 context.Context 参数，返回其名称，没有则返回空串
+func enclosingContextParam(pass *analysis.Pass, stack []ast.Node) string {
+	for i := len(stack) - 1; i >= 0; i-- {
+		var ft *ast.FuncType
+		switch f := stack[i].(type) {
+		case *ast.FuncDecl:
+			ft = f.Type
+		case *ast.FuncLit:
+			ft = f.Type
+		default:
+			continue
+		}
+		for _, field := range ft.Params.List {
+			if !isContext(pass.TypesInfo.TypeOf(field.Type)) {
+				continue
+			}
+			for _, id := range field.Names {
+				if id.Name != "_" {
+					return id.Name
+				}
+			}
+		}
+		// 闭包自身没有 ctx 参数时仍可捕获外层函数的 ctx
+	}
+	return ""
+}
+
+// promiseMethod 若 call 为 *promise.Promise[T] 的方法调用，返回方法名和接收者表达式
+func promiseMethod(pass *analysis.Pass, call *ast.CallExpr) (string, ast.Expr) {
+	sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+	if !ok {
+		return "", nil
+	}
+	s, ok := pass.TypesInfo.Selections[sel]
+	if !ok || s.Kind() != types.MethodVal || !isPromise(s.Recv()) {
+		return "", nil
+	}
+	return sel.Sel.Name, sel.X
+}
+
+// calledFunc 返回被调用的具名函数 (含泛型实例化)
+func calledFunc(pass *analysis.Pass, call *ast.CallExpr) *types.Func {
+	fun := ast.Unparen(call.Fun)
+	switch f := fun.(type) {
+	case *ast.IndexExpr:
+		fun = f.X
+	case *ast.IndexListExpr:
+		fun = f.X
+	}
+	var id *ast.Ident
+	switch f := fun.(type) {
+	case *ast.Ident:
+		id = f
+	case *ast.SelectorExpr:
+		id = f.Sel
+	default:
+		return nil
+	}
+	fn, _ := pass.TypesInfo.Uses[id].(*types.Func)
+	return fn
+}
+
+// callName 诊断信息中展示的被调用函数名
+func callName(call *ast.CallExpr) string {
+	fun := ast.Unparen(call.Fun)
+	switch f := fun.(type) {
+	case *ast.IndexExpr:
+		fun = f.X
+	case *ast.IndexListExpr:
+		fun = f.X
+	}
+	switch f := fun.(type) {
+	case *ast.Ident:
+		return f.Name
+	case *ast.SelectorExpr:
+		if x, ok := f.X.(*ast.Ident); ok {
+			return x.Name + "." + f.Sel.Name
+		}
+		return f.Sel.Name
+	default:
+		return "call"
+	}
+}
+
+// isPromise t 是否为 *promise.Promise[T] 或 *promise.Pooled[T]
+func isPromise(t types.Type) bool {
+	ptr, ok := t.(*types.Pointer)
+	if !ok {
+		return false
+	}
+	named, ok := ptr.Elem().(*types.Named)
+	if !ok {
+		return false
+	}
+	// Pooled 嵌入 Promise，方法与结果的处理方式相同
+	obj := named.Obj()
+	return (obj.Name() == "Promise" || obj.Name() == "Pooled") && obj.Pkg() != nil && obj.Pkg().Path() == promisePkg
+}
+
+func isContext(t types.Type) bool {
+	named, ok := t.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Name() == "Context" && obj.Pkg() != nil && obj.Pkg().Path() == "context"
+}
+
+func isNil(pass *analysis.Pass, e ast.Expr) bool {
+	tv, ok := pass.TypesInfo.Types[e]
+	return ok && tv.IsNil()
+}
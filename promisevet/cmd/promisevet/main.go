@@ -0,0 +1,15 @@
+// Command promisevet 报告未 Await、未处理拒绝的 Promise 以及忽略 ctx 的 Await 调用。
+//
+//	go install github.com/xigexb/go-promise/promisevet/cmd/promisevet@latest
+//	promisevet ./...
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/singlechecker"
+
+	"github.com/xigexb/go-promise/promisevet"
+)
+
+func main() {
+	singlechecker.Main(promisevet.Analyzer)
+}
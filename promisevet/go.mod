@@ -0,0 +1,11 @@
+module github.com/xigexb/go-promise/promisevet
+
+// go 1.22 是 x/tools 的要求：v0.24 及更早的版本无法用当前的 Go 工具链编译 (internal/tokeninternal)
+go 1.22.0
+
+require golang.org/x/tools v0.30.0
+
+require (
+	golang.org/x/mod v0.23.0 // indirect
+	golang.org/x/sync v0.11.0 // indirect
+)
@@ -0,0 +1,64 @@
+package a
+
+import (
+	"context"
+
+	"github.com/xigexb/go-promise/promise"
+)
+
+func load() *promise.Promise[int] { return promise.Resolve(1) }
+
+func discarded() {
+	load()                                                              // want `result of load is discarded: Await it or attach a Catch`
+	promise.Resolve(1)                                                  // want `result of promise.Resolve is discarded`
+	promise.Resolve[int](1)                                             // want `result of promise.Resolve is discarded`
+	promise.Map(load(), func(v int) (string, error) { return "", nil }) // want `result of promise.Map is discarded`
+
+	_ = load() // 显式忽略
+	p := load()
+	_, _ = p.Await(context.Background())
+}
+
+func chains(p *promise.Promise[int]) {
+	p.Then(func(v int) int { return v }, nil)                                             // want `promise chain ends with Then without a rejection handler`
+	p.Finally(func() {})                                                                  // want `promise chain ends with Finally without a rejection handler`
+	p.Catch(func(err error) error { return err }).Then(func(v int) int { return v }, nil) // want `promise chain ends with Then`
+
+	p.Then(func(v int) int { return v }, func(err error) error { return nil })
+	p.Then(func(v int) int { return v }, nil).Catch(func(err error) error { return nil })
+	p.Tap(func(v int, err error) {})
+	p.ThenSync(func(v int) int { return v }, nil) // want `promise chain ends with ThenSync without a rejection handler`
+	p.ThenSync(func(v int) int { return v }, func(err error) error { return nil })
+}
+
+func withCtx(ctx context.Context, p *promise.Promise[int]) {
+	_, _ = p.Await(context.Background()) // want `Await\(context.Background\(\)\) ignores cancellation: pass ctx instead`
+	_, _ = p.Await(context.TODO())       // want `Await\(context.TODO\(\)\) ignores cancellation: pass ctx instead`
+	_, _ = p.Await(ctx)
+	_, _ = p.Wait() // want `Wait\(\) ignores cancellation: use Await\(ctx\) instead`
+
+	go func() {
+		_, _ = p.Await(context.Background()) // want `pass ctx instead`
+	}()
+	go func(inner context.Context) {
+		_, _ = p.Await(context.Background()) // want `pass inner instead`
+	}(ctx)
+}
+
+func withoutCtx(p *promise.Promise[int]) {
+	_, _ = p.Await(context.Background())
+	_, _ = p.Wait()
+}
+
+func pooled(ctx context.Context, pool *promise.PromisePool[int]) {
+	pool.Get()                                                           // want `result of pool.Get is discarded`
+	pool.New(func(resolve func(int), reject func(error)) { resolve(1) }) // want `result of pool.New is discarded`
+
+	p := pool.Get()
+	p.Then(func(v int) int { return v }, nil) // want `promise chain ends with Then without a rejection handler`
+	p.Catch(func(err error) error { return nil })
+	_, _ = p.Wait()                      // want `Wait\(\) ignores cancellation: use Await\(ctx\) instead`
+	_, _ = p.Await(context.Background()) // want `pass ctx instead`
+	_, _ = p.Await(ctx)
+	p.Release()
+}
@@ -0,0 +1,52 @@
+// Package promise 是测试用的最小桩实现，只保留分析器关心的签名
+package promise
+
+import "context"
+
+type Promise[T any] struct{}
+
+func New[T any](executor func(resolve func(T), reject func(error))) *Promise[T] { return nil }
+
+func Resolve[T any](val T) *Promise[T] { return nil }
+
+func All[T any](promises ...*Promise[T]) *Promise[[]T] { return nil }
+
+func Map[T any, R any](p *Promise[T], mapper func(T) (R, error)) *Promise[R] { return nil }
+
+func (p *Promise[T]) Then(onFulfilled func(T) T, onRejected func(error) error) *Promise[T] {
+	return p
+}
+
+func (p *Promise[T]) ThenSync(onFulfilled func(T) T, onRejected func(error) error) *Promise[T] {
+	return p
+}
+
+func (p *Promise[T]) Catch(onRejected func(error) error) *Promise[T] { return p }
+
+func (p *Promise[T]) Finally(onFinally func()) *Promise[T] { return p }
+
+func (p *Promise[T]) Tap(onTap func(val T, err error)) *Promise[T] { return p }
+
+func (p *Promise[T]) Await(ctx context.Context) (T, error) {
+	var zero T
+	return zero, nil
+}
+
+func (p *Promise[T]) Wait() (T, error) {
+	var zero T
+	return zero, nil
+}
+
+type Pooled[T any] struct {
+	Promise[T]
+}
+
+func (p *Pooled[T]) Release() {}
+
+type PromisePool[T any] struct{}
+
+func (pp *PromisePool[T]) Get() *Pooled[T] { return nil }
+
+func (pp *PromisePool[T]) New(executor func(resolve func(T), reject func(error))) *Pooled[T] {
+	return nil
+}